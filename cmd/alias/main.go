@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"cricket/cmd/app"
+	"cricket/internal"
+)
+
+const usage = `usage:
+  go run ./cmd/alias show -player 12
+  go run ./cmd/alias merge -player 12 -duplicate 34
+  go run ./cmd/alias split -player 12 -source_id 7b9b9aef
+  go run ./cmd/alias rename -player 12 -name "Sher Muhammad"`
+
+// admin command to merge or split player identities, same as the admin api
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	flags := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	playerId := flags.Int("player", 0, "id of the player")
+	duplicateId := flags.Int("duplicate", 0, "id of the player merged into -player")
+	sourceId := flags.String("source_id", "", "source id split out of -player into a new player")
+	name := flags.String("name", "", "canonical display name of -player")
+	_ = flags.Parse(os.Args[2:])
+	if *playerId == 0 {
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+
+	service := app.InitializeApp()
+
+	var identity internal.PlayerIdentity
+	var err error
+	switch os.Args[1] {
+	case "show":
+		identity, err = internal.QueryPlayerIdentity(*playerId, service)
+	case "merge":
+		identity, err = internal.MergePlayers(*playerId, *duplicateId, service)
+	case "split":
+		identity, err = internal.SplitPlayer(*playerId, *sourceId, service)
+	case "rename":
+		identity, err = internal.RenamePlayer(*playerId, *name, service)
+	default:
+		fmt.Println(usage)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Println("error:", err.Error())
+		os.Exit(1)
+	}
+
+	output, _ := json.MarshalIndent(identity, "", "  ")
+	fmt.Println(string(output))
+}
@@ -1,12 +1,15 @@
 package main
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"os"
 
 	"cricket/cmd/app"
 	"cricket/service/router"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
 	"go.uber.org/zap"
 )
@@ -26,6 +29,14 @@ func main() {
 	}
 }
 
+const adminTokenEnv = "CRICKET_ADMIN_TOKEN"
+
+func adminTokenValidator(adminToken string) middleware.KeyAuthValidator {
+	return func(key string, c echo.Context) (bool, error) {
+		return subtle.ConstantTimeCompare([]byte(key), []byte(adminToken)) == 1, nil
+	}
+}
+
 func healthCheck(c echo.Context) error {
 	response := map[string]string{"status": "Hello world"}
 	return c.JSON(http.StatusOK, response)
@@ -36,4 +47,22 @@ func AddRouter(e *echo.Echo, service *app.App) {
 
 	tournamentRouter := v1.Group("/tournament")
 	router.AddTournamentRouters(tournamentRouter, service)
+
+	matchRouter := v1.Group("/match")
+	router.AddMatchRouters(matchRouter, service)
+
+	playerRouter := v1.Group("/player")
+	router.AddPlayerRouters(playerRouter, service)
+
+	teamRouter := v1.Group("/team")
+	router.AddTeamRouters(teamRouter, service)
+
+	// admin api changes player data, it is served only when a token is configured and every request has to send it
+	// as Authorization: Bearer <token>. cmd/alias does the same operations without the server.
+	if adminToken := os.Getenv(adminTokenEnv); adminToken != "" {
+		adminRouter := v1.Group("/admin", middleware.KeyAuth(adminTokenValidator(adminToken)))
+		router.AddAdminRouters(adminRouter, service)
+	} else {
+		service.Logger.Info("admin api is disabled, set " + adminTokenEnv + " to enable it")
+	}
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"cricket/cmd/app"
+	"cricket/internal"
+)
+
+// imports the cricsheet registry files downloaded from https://cricsheet.org/register/
+// names are stored only for the players of ingested matches, run it after ingesting the matches.
+func main() {
+	peoplePath := flag.String("people", "", "path of people.csv")
+	namesPath := flag.String("names", "", "path of names.csv")
+	flag.Parse()
+	if *peoplePath == "" && *namesPath == "" {
+		fmt.Println("usage: go run ./cmd/registry -people people.csv -names names.csv")
+		os.Exit(1)
+	}
+
+	service := app.InitializeApp()
+	summary, err := internal.ImportRegistry(*peoplePath, *namesPath, service)
+	if err != nil {
+		fmt.Println("error:", err.Error())
+		os.Exit(1)
+	}
+
+	output, _ := json.MarshalIndent(summary, "", "  ")
+	fmt.Println(string(output))
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"cricket/cmd/app"
+	"cricket/internal"
+)
+
+// listFlag flag given more than once or as comma separated values, eg: -input odis_json -input tests_json
+type listFlag []string
+
+func (list *listFlag) String() string {
+	return strings.Join(*list, ",")
+}
+
+func (list *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*list = append(*list, item)
+		}
+	}
+	return nil
+}
+
+/*
+ingests cricsheet match files, eg:
+
+	go run ./cmd/script -input odis_json -input "tests_json/*.json" -gender female -limit 100
+	go run ./cmd/script -input t20s_male_json.zip -input odis_json.zip -workers 8
+
+t20s_male_json directory is read when no input is given.
+*/
+func main() {
+	config := internal.IngestConfig{}
+	flag.Var((*listFlag)(&config.Inputs), "input", "directory, zip archive or glob pattern of match files, can be repeated")
+	flag.Var((*listFlag)(&config.IncludeMatchTypes), "match-type", "ingest only these match types, eg: T20,ODI")
+	flag.Var((*listFlag)(&config.ExcludeMatchTypes), "exclude-match-type", "skip these match types, eg: Test")
+	flag.Var((*listFlag)(&config.IncludeGenders), "gender", "ingest only these genders, eg: female")
+	flag.Var((*listFlag)(&config.ExcludeGenders), "exclude-gender", "skip these genders")
+	flag.IntVar(&config.Limit, "limit", 0, "maximum number of matches ingested, 0 is no limit")
+	flag.IntVar(&config.Workers, "workers", 1, "number of matches ingested in parallel")
+	flag.Parse()
+
+	service := app.InitializeApp()
+	service.Logger.Info("app initiated")
+
+	if err := internal.ReadData(config, service); err != nil {
+		fmt.Println("error:", err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,250 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	ErrSamePlayer      = errors.New("player cannot be merged into itself")
+	ErrAliasNotFound   = errors.New("source id is not an alias of the player")
+	ErrPrimarySourceId = errors.New("source id is the primary id of the player, merge it into another player instead")
+	ErrEmptyName       = errors.New("name cannot be empty")
+)
+
+type PlayerAlias struct {
+	SourceId string `db:"source_id" json:"source_id"`
+	Name     string `db:"name" json:"name"`
+}
+
+// PlayerIdentity name is the canonical display name of the player, aliases are the names
+// of the player in the match files along with their source_id. identifiers are from the cricsheet registry.
+type PlayerIdentity struct {
+	PlayerId    int                `json:"player_id"`
+	Name        string             `json:"name"`
+	SourceId    string             `json:"source_id"`
+	Aliases     []PlayerAlias      `json:"aliases"`
+	Identifiers []PlayerIdentifier `json:"identifiers"`
+}
+
+// playerReferences columns referring to player in the tables with an event column, used while
+// moving the matches from one player to another
+var playerReferences = []struct {
+	table  string
+	column string
+}{
+	{"ball_info", "batsman"},
+	{"ball_info", "bowler"},
+	{"ball_info", "non_striker"},
+	{"wicket", "player"},
+	{"wicket", "bowler"},
+	{"end_result", "player_of_the_match"},
+	{"match_player", "player"},
+}
+
+// QueryPlayerIdentity canonical name and aliases of the player
+func QueryPlayerIdentity(playerId int, appInstance *app.App) (PlayerIdentity, error) {
+	return playerIdentity(playerId, appInstance.DB)
+}
+
+func playerIdentity(playerId int, dbPool *pgxpool.Pool) (PlayerIdentity, error) {
+	sqlQuery := `SELECT name, COALESCE(source_id, '') FROM player WHERE id = @player`
+	namedArgs := pgx.NamedArgs{"player": playerId}
+
+	identity := PlayerIdentity{PlayerId: playerId}
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&identity.Name, &identity.SourceId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PlayerIdentity{}, ErrPlayerNotFound
+	}
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+
+	sqlQuery = `SELECT source_id, name FROM player_alias WHERE player = @player ORDER BY source_id, name`
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	identity.Aliases, err = pgx.CollectRows(rows, pgx.RowToStructByName[PlayerAlias])
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	identity.Identifiers, err = playerIdentifiers(playerId, dbPool)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	return identity, nil
+}
+
+// RenamePlayer sets the canonical display name of the player
+func RenamePlayer(playerId int, name string, appInstance *app.App) (PlayerIdentity, error) {
+	if name == "" {
+		return PlayerIdentity{}, ErrEmptyName
+	}
+	sqlQuery := `UPDATE player SET name = @name, updated_at = CURRENT_TIMESTAMP WHERE id = @player`
+	namedArgs := pgx.NamedArgs{"player": playerId, "name": name}
+
+	tag, err := appInstance.DB.Exec(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return PlayerIdentity{}, ErrPlayerNotFound
+	}
+	return playerIdentity(playerId, appInstance.DB)
+}
+
+// MergePlayers merges the duplicate into the player. matches and aliases of the duplicate are moved
+// to the player and the duplicate is removed, so the files with its source_id resolve to the player.
+func MergePlayers(playerId int, duplicateId int, appInstance *app.App) (PlayerIdentity, error) {
+	if playerId == duplicateId {
+		return PlayerIdentity{}, ErrSamePlayer
+	}
+	for _, id := range []int{playerId, duplicateId} {
+		if _, err := getPlayerName(id, appInstance.DB); err != nil {
+			return PlayerIdentity{}, err
+		}
+	}
+
+	tx, err := appInstance.DB.Begin(context.TODO())
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	defer func(tx pgx.Tx) {
+		_ = tx.Rollback(context.TODO())
+	}(tx)
+
+	err = movePlayerReferences(tx, duplicateId, playerId, nil)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	namedArgs := pgx.NamedArgs{"from": duplicateId, "to": playerId}
+	sqlQueries := []string{
+		`UPDATE player_alias SET player = @to, updated_at = CURRENT_TIMESTAMP WHERE player = @from`,
+		`DELETE FROM player WHERE id = @from`,
+	}
+	for _, sqlQuery := range sqlQueries {
+		if _, err = tx.Exec(context.TODO(), sqlQuery, namedArgs); err != nil {
+			return PlayerIdentity{}, err
+		}
+	}
+	if err = tx.Commit(context.TODO()); err != nil {
+		return PlayerIdentity{}, err
+	}
+	return playerIdentity(playerId, appInstance.DB)
+}
+
+// SplitPlayer moves the source_id out of the player into a new player along with its aliases and the
+// matches it was resolved in. primary source_id of the player cannot be split, as it identifies the player.
+func SplitPlayer(playerId int, sourceId string, appInstance *app.App) (PlayerIdentity, error) {
+	identity, err := playerIdentity(playerId, appInstance.DB)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	if identity.SourceId == sourceId {
+		return PlayerIdentity{}, ErrPrimarySourceId
+	}
+	name := ""
+	for _, alias := range identity.Aliases {
+		if alias.SourceId == sourceId {
+			name = displayName(alias.Name)
+			break
+		}
+	}
+	if name == "" {
+		return PlayerIdentity{}, ErrAliasNotFound
+	}
+
+	tx, err := appInstance.DB.Begin(context.TODO())
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	defer func(tx pgx.Tx) {
+		_ = tx.Rollback(context.TODO())
+	}(tx)
+
+	sqlQuery := `
+		INSERT INTO player (name, source_id, team_id)
+		SELECT @name, @source_id, team_id FROM player WHERE id = @player
+		RETURNING id`
+	namedArgs := pgx.NamedArgs{"name": name, "source_id": sourceId, "player": playerId}
+	var newPlayerId int
+	err = tx.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&newPlayerId)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+
+	events, err := sourceIdEvents(tx, playerId, sourceId)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	err = movePlayerReferences(tx, playerId, newPlayerId, events)
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	namedArgs = pgx.NamedArgs{"from": playerId, "to": newPlayerId, "source_id": sourceId}
+	sqlQuery = `UPDATE player_alias SET player = @to, updated_at = CURRENT_TIMESTAMP WHERE player = @from AND source_id = @source_id`
+	if _, err = tx.Exec(context.TODO(), sqlQuery, namedArgs); err != nil {
+		return PlayerIdentity{}, err
+	}
+	if err = tx.Commit(context.TODO()); err != nil {
+		return PlayerIdentity{}, err
+	}
+	return playerIdentity(newPlayerId, appInstance.DB)
+}
+
+// sourceIdEvents events in which the player was resolved from the source_id. an empty slice is
+// returned instead of nil when there are no such events, so that no event is considered.
+func sourceIdEvents(tx pgx.Tx, playerId int, sourceId string) ([]int, error) {
+	sqlQuery := `SELECT event FROM match_player WHERE player = @player AND source_id = @source_id`
+	namedArgs := pgx.NamedArgs{"player": playerId, "source_id": sourceId}
+	rows, err := tx.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = make([]int, 0)
+	}
+	return events, nil
+}
+
+// movePlayerReferences moves every reference of the player in the given events to another player.
+// references in all the events are moved when events is nil.
+func movePlayerReferences(tx pgx.Tx, from int, to int, events []int) error {
+	eventCondition := "(@events::int[] IS NULL OR %s = ANY(@events))"
+	sqlQueries := make([]string, 0, len(playerReferences)+2)
+	for _, reference := range playerReferences {
+		sqlQueries = append(sqlQueries, fmt.Sprintf(
+			"UPDATE %[1]s SET %[2]s = @to WHERE %[2]s = @from AND "+eventCondition,
+			reference.table, reference.column, "event",
+		))
+	}
+	sqlQueries = append(sqlQueries,
+		fmt.Sprintf(`
+		UPDATE wicket_fielder SET player = @to
+		WHERE player = @from AND wicket IN (SELECT id FROM wicket WHERE `+eventCondition+`)`, "event"),
+		fmt.Sprintf(`
+		UPDATE event SET
+		    playing_11_a_ids = array_replace(playing_11_a_ids, @from, @to),
+		    playing_11_b_ids = array_replace(playing_11_b_ids, @from, @to)
+		WHERE (@from = ANY(playing_11_a_ids) OR @from = ANY(playing_11_b_ids)) AND `+eventCondition, "id"),
+	)
+
+	namedArgs := pgx.NamedArgs{"from": from, "to": to, "events": events}
+	for _, sqlQuery := range sqlQueries {
+		if _, err := tx.Exec(context.TODO(), sqlQuery, namedArgs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	jsonparser "cricket/pkg/json_parser"
+)
+
+var (
+	ErrMissingInfoFile = errors.New("match info file is missing, expected <match>_info.csv next to the match file")
+	ErrMissingRegistry = errors.New("match file does not have the player registry")
+)
+
+// infoFileSuffix cricsheet csv ("Ashwin") format has the balls in 1234.csv and the match info in 1234_info.csv
+const infoFileSuffix = "_info.csv"
+
+func isCsvFile(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".csv")
+}
+
+func isCsvInfoFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), infoFileSuffix)
+}
+
+// csvInfoPath path of the info file of the csv match file
+func csvInfoPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + infoFileSuffix
+}
+
+/*
+decodeCsvMatch decodes a match of the cricsheet csv format into the same structure as the json files.
+
+balls has one row per delivery with the header
+match_id,season,start_date,venue,innings,ball,batting_team,bowling_team,striker,non_striker,bowler,
+runs_off_bat,extras,wides,noballs,byes,legbyes,penalty,wicket_type,player_dismissed,other_wicket_type,other_player_dismissed
+
+info has one row per value like info,team,Kolkata Knight Riders and info,registry,people,SC Ganguly,<source_id>.
+the format does not have fielders and targets, so they are not part of the match.
+*/
+func decodeCsvMatch(balls []byte, info []byte) (baseStruct, error) {
+	if info == nil {
+		return baseStruct{}, ErrMissingInfoFile
+	}
+	matchInfo, err := decodeCsvInfo(info)
+	if err != nil {
+		return baseStruct{}, fmt.Errorf("reading info: %w", err)
+	}
+	if len(matchInfo.Registry.People) == 0 {
+		return baseStruct{}, ErrMissingRegistry
+	}
+	if err = checkMatchInfo(matchInfo); err != nil {
+		return baseStruct{}, err
+	}
+	innings, err := decodeCsvBalls(balls, matchInfo)
+	if err != nil {
+		return baseStruct{}, fmt.Errorf("reading balls: %w", err)
+	}
+	return baseStruct{Info: matchInfo, Innings: innings}, nil
+}
+
+func decodeCsvInfo(content []byte) (jsonparser.Info, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	// rows have different number of columns, eg: info,team,X and info,player,X,Y
+	reader.FieldsPerRecord = -1
+
+	info := jsonparser.Info{
+		Toss:     make(map[string]string),
+		Players:  make(map[string][]string),
+		Registry: jsonparser.Registry{People: make(map[string]string)},
+	}
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return info, nil
+		}
+		if err != nil {
+			return jsonparser.Info{}, err
+		}
+		if len(row) < 3 || row[0] != "info" {
+			// version row and blank rows
+			continue
+		}
+
+		key, value := row[1], row[2]
+		switch key {
+		case "team":
+			info.Teams = append(info.Teams, value)
+		case "date":
+			// dates are written as 2008/04/18
+			info.Dates = append(info.Dates, strings.ReplaceAll(value, "/", "-"))
+		case "gender":
+			info.Gender = value
+		case "season":
+			info.Season = value
+		case "match_type":
+			info.MatchType = value
+		case "match_type_number":
+			info.MatchTypeNumber, _ = strconv.Atoi(value)
+		case "team_type":
+			info.TeamType = value
+		case "balls_per_over":
+			info.BallsPerOver, _ = strconv.Atoi(value)
+		case "overs":
+			info.Overs, _ = strconv.Atoi(value)
+		case "event":
+			info.MatchEvent.Name = value
+		case "match_number":
+			info.MatchEvent.MatchNumber, _ = strconv.Atoi(value)
+		case "venue":
+			info.Venue = value
+		case "city":
+			info.City = value
+		case "toss_winner":
+			info.Toss["winner"] = value
+		case "toss_decision":
+			info.Toss["decision"] = value
+		case "player_of_match":
+			info.PlayerOfMatch = append(info.PlayerOfMatch, value)
+		case "winner":
+			info.Outcome.Winner = value
+		case "winner_runs":
+			info.Outcome.By.Runs, _ = strconv.Atoi(value)
+		case "winner_wickets":
+			info.Outcome.By.Wickets, _ = strconv.Atoi(value)
+		case "winner_innings":
+			info.Outcome.By.Innings, _ = strconv.Atoi(value)
+		case "outcome":
+			info.Outcome.Result = value
+		case "method":
+			info.Outcome.Method = value
+		case "eliminator":
+			info.Outcome.Eliminator = value
+		case "bowl_out":
+			info.Outcome.BowlOut = value
+		case "player":
+			if len(row) > 3 {
+				info.Players[value] = append(info.Players[value], row[3])
+			}
+		case "registry":
+			// info,registry,people,<name>,<source_id>
+			if value == "people" && len(row) > 4 {
+				info.Registry.People[row[3]] = row[4]
+			}
+		}
+	}
+}
+
+// decodeCsvBalls groups the balls into innings and overs in the order they are written.
+// innings after the second are super overs in limited overs matches. matches spanning more than a day
+// are treated as multi day matches, as the format does not always have the match type.
+func decodeCsvBalls(content []byte, info jsonparser.Info) ([]jsonparser.Innings, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	columns, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	header := make(map[string]int)
+	for index, column := range columns {
+		header[strings.TrimSpace(column)] = index
+	}
+	for _, column := range []string{"innings", "ball", "batting_team", "striker", "non_striker", "bowler"} {
+		if _, ok := header[column]; !ok {
+			return nil, fmt.Errorf("column %s is missing", column)
+		}
+	}
+	multiDay := len(info.Dates) > 1 || info.MatchType == "Test" || info.MatchType == "MDM"
+
+	field := func(row []string, column string) string {
+		index, ok := header[column]
+		if !ok || index >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[index])
+	}
+	number := func(row []string, column string) int {
+		value, _ := strconv.Atoi(field(row, column))
+		return value
+	}
+
+	innings := make([]jsonparser.Innings, 0)
+	inningsNumber := 0
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return innings, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if number(row, "innings") != inningsNumber {
+			inningsNumber = number(row, "innings")
+			innings = append(innings, jsonparser.Innings{
+				Team:      field(row, "batting_team"),
+				SuperOver: inningsNumber > 2 && !multiDay,
+			})
+		}
+		current := &innings[len(innings)-1]
+
+		over, err := strconv.Atoi(strings.Split(field(row, "ball"), ".")[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ball %s", field(row, "ball"))
+		}
+		if len(current.Over) == 0 || current.Over[len(current.Over)-1].OverCount != over {
+			current.Over = append(current.Over, jsonparser.MatchOver{OverCount: over})
+		}
+
+		delivery := jsonparser.Delivery{
+			Batter:     field(row, "striker"),
+			Bowler:     field(row, "bowler"),
+			NonStriker: field(row, "non_striker"),
+			Runs: jsonparser.Run{
+				Batter: number(row, "runs_off_bat"),
+				Extras: number(row, "extras"),
+				Total:  number(row, "runs_off_bat") + number(row, "extras"),
+			},
+			Extras: jsonparser.Extras{
+				Wides:   number(row, "wides"),
+				NoBalls: number(row, "noballs"),
+				Byes:    number(row, "byes"),
+				LegByes: number(row, "legbyes"),
+				Penalty: number(row, "penalty"),
+			},
+		}
+		for _, prefix := range []string{"", "other_"} {
+			if kind := field(row, prefix+"wicket_type"); kind != "" {
+				delivery.Wicket = append(delivery.Wicket, jsonparser.Wicket{
+					Kind:      kind,
+					PlayerOut: field(row, prefix+"player_dismissed"),
+				})
+			}
+		}
+
+		matchOver := &current.Over[len(current.Over)-1]
+		matchOver.Deliveries = append(matchOver.Deliveries, delivery)
+	}
+}
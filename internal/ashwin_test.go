@@ -0,0 +1,273 @@
+package internal
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	jsonparser "cricket/pkg/json_parser"
+)
+
+const csvInfoFixture = `version,1.6.0
+info,team,Kolkata Knight Riders
+info,team,Royal Challengers Bangalore
+info,gender,male
+info,season,2007/08
+info,date,2008/04/18
+info,match_type,T20
+info,match_type_number,1
+info,overs,20
+info,event,Indian Premier League
+info,match_number,1
+info,venue,M Chinnaswamy Stadium
+info,toss_winner,Royal Challengers Bangalore
+info,toss_decision,field
+info,player_of_match,BB McCullum
+info,winner,Kolkata Knight Riders
+info,winner_runs,140
+info,player,Kolkata Knight Riders,SC Ganguly
+info,player,Kolkata Knight Riders,BB McCullum
+info,player,Royal Challengers Bangalore,P Kumar
+info,player,Royal Challengers Bangalore,Z Khan
+info,registry,people,SC Ganguly,6e3e5b5d
+info,registry,people,BB McCullum,4b8f3a5c
+info,registry,people,P Kumar,2e3f4a5b
+info,registry,people,Z Khan,7a8b9c0d
+`
+
+const csvBallsHeader = "match_id,season,start_date,venue,innings,ball,batting_team,bowling_team,striker,non_striker,bowler," +
+	"runs_off_bat,extras,wides,noballs,byes,legbyes,penalty,wicket_type,player_dismissed,other_wicket_type,other_player_dismissed\n"
+
+func TestDecodeCsvInfo(t *testing.T) {
+	info, err := decodeCsvInfo([]byte(csvInfoFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(info.Teams, []string{"Kolkata Knight Riders", "Royal Challengers Bangalore"}) {
+		t.Errorf("teams = %v", info.Teams)
+	}
+	if !slices.Equal(info.Dates, []string{"2008-04-18"}) {
+		t.Errorf("dates = %v, want [2008-04-18]", info.Dates)
+	}
+	if info.MatchType != "T20" || info.MatchTypeNumber != 1 || info.Overs != 20 || info.Season != "2007/08" {
+		t.Errorf("info = %+v", info)
+	}
+	if info.MatchEvent.Name != "Indian Premier League" || info.MatchEvent.MatchNumber != 1 {
+		t.Errorf("event = %+v", info.MatchEvent)
+	}
+	if info.Toss["winner"] != "Royal Challengers Bangalore" || info.Toss["decision"] != "field" {
+		t.Errorf("toss = %v", info.Toss)
+	}
+	if info.Outcome.Winner != "Kolkata Knight Riders" || info.Outcome.By.Runs != 140 {
+		t.Errorf("outcome = %+v", info.Outcome)
+	}
+	if !slices.Equal(info.Players["Kolkata Knight Riders"], []string{"SC Ganguly", "BB McCullum"}) {
+		t.Errorf("players = %v", info.Players)
+	}
+	if len(info.Registry.People) != 4 || info.Registry.People["Z Khan"] != "7a8b9c0d" {
+		t.Errorf("registry = %v", info.Registry.People)
+	}
+}
+
+func TestDecodeCsvBalls(t *testing.T) {
+	limitedOvers := jsonparser.Info{MatchType: "T20", Dates: []string{"2008-04-18"}}
+	tests := []struct {
+		name      string
+		info      jsonparser.Info
+		balls     string
+		superOver []bool
+		overs     []int
+		wickets   []jsonparser.Wicket
+		wantErr   bool
+	}{
+		{
+			name: "innings after the second of a limited overs match are super overs",
+			info: limitedOvers,
+			balls: "1,2008,2008-04-18,V,1,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,1,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,2,0.1,B,A,P Kumar,Z Khan,SC Ganguly,0,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,3,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,6,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,4,0.1,B,A,P Kumar,Z Khan,SC Ganguly,4,0,,,,,,,,,\n",
+			superOver: []bool{false, false, true, true},
+			overs:     []int{1, 1, 1, 1},
+		},
+		{
+			name: "third innings of a match over more than a day is not a super over",
+			info: jsonparser.Info{Dates: []string{"2008-04-18", "2008-04-19"}},
+			balls: "1,2008,2008-04-18,V,1,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,1,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,2,0.1,B,A,P Kumar,Z Khan,SC Ganguly,0,0,,,,,,,,,\n" +
+				"1,2008,2008-04-19,V,3,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,6,0,,,,,,,,,\n",
+			superOver: []bool{false, false, false},
+			overs:     []int{1, 1, 1},
+		},
+		{
+			name: "third innings of a one day test is not a super over",
+			info: jsonparser.Info{MatchType: "Test", Dates: []string{"2008-04-18"}},
+			balls: "1,2008,2008-04-18,V,1,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,1,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,2,0.1,B,A,P Kumar,Z Khan,SC Ganguly,0,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,3,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,6,0,,,,,,,,,\n",
+			superOver: []bool{false, false, false},
+			overs:     []int{1, 1, 1},
+		},
+		{
+			name: "balls are grouped into overs",
+			info: limitedOvers,
+			balls: "1,2008,2008-04-18,V,1,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,1,0,,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,1,0.2,A,B,BB McCullum,SC Ganguly,P Kumar,0,1,1,,,,,,,,\n" +
+				"1,2008,2008-04-18,V,1,1.1,A,B,BB McCullum,SC Ganguly,Z Khan,4,0,,,,,,,,,\n",
+			superOver: []bool{false},
+			overs:     []int{2},
+		},
+		{
+			name: "wicket and other wicket columns",
+			info: limitedOvers,
+			balls: "1,2008,2008-04-18,V,1,0.1,A,B,SC Ganguly,BB McCullum,P Kumar,0,0,,,,,,caught,SC Ganguly,,\n" +
+				"1,2008,2008-04-18,V,1,0.2,A,B,BB McCullum,RT Ponting,P Kumar,0,0,,,,,,run out,BB McCullum,retired hurt,RT Ponting\n",
+			superOver: []bool{false},
+			overs:     []int{1},
+			wickets: []jsonparser.Wicket{
+				{Kind: "caught", PlayerOut: "SC Ganguly"},
+				{Kind: "run out", PlayerOut: "BB McCullum"},
+				{Kind: "retired hurt", PlayerOut: "RT Ponting"},
+			},
+		},
+		{
+			name:    "invalid ball",
+			info:    limitedOvers,
+			balls:   "1,2008,2008-04-18,V,1,x.1,A,B,SC Ganguly,BB McCullum,P Kumar,0,0,,,,,,,,,\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			innings, err := decodeCsvBalls([]byte(csvBallsHeader+test.balls), test.info)
+			if test.wantErr {
+				if err == nil {
+					t.Fatal("decodeCsvBalls error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(innings) != len(test.superOver) {
+				t.Fatalf("innings = %d, want %d", len(innings), len(test.superOver))
+			}
+			wickets := make([]jsonparser.Wicket, 0)
+			for index, item := range innings {
+				if item.SuperOver != test.superOver[index] {
+					t.Errorf("super over of innings %d = %v, want %v", index+1, item.SuperOver, test.superOver[index])
+				}
+				if len(item.Over) != test.overs[index] {
+					t.Errorf("overs of innings %d = %d, want %d", index+1, len(item.Over), test.overs[index])
+				}
+				for _, over := range item.Over {
+					for _, delivery := range over.Deliveries {
+						wickets = append(wickets, delivery.Wicket...)
+					}
+				}
+			}
+			if len(test.wickets) > 0 && !slices.EqualFunc(wickets, test.wickets, func(a, b jsonparser.Wicket) bool {
+				return a.Kind == b.Kind && a.PlayerOut == b.PlayerOut
+			}) {
+				t.Errorf("wickets = %+v, want %+v", wickets, test.wickets)
+			}
+		})
+	}
+}
+
+func TestDecodeCsvBallsMissingColumn(t *testing.T) {
+	_, err := decodeCsvBalls([]byte("match_id,innings,ball\n1,1,0.1\n"), jsonparser.Info{})
+	if err == nil {
+		t.Fatal("decodeCsvBalls error = nil, want missing column error")
+	}
+}
+
+func TestDecodeCsvMatch(t *testing.T) {
+	balls := []byte(csvBallsHeader +
+		"1,2008,2008-04-18,V,1,0.1,Kolkata Knight Riders,Royal Challengers Bangalore,SC Ganguly,BB McCullum,P Kumar,0,1,,,,1,,,,,\n")
+	tests := []struct {
+		name    string
+		info    []byte
+		wantErr error
+	}{
+		{name: "match", info: []byte(csvInfoFixture)},
+		{name: "info file is missing", info: nil, wantErr: ErrMissingInfoFile},
+		{name: "info without registry", info: []byte("info,team,A\ninfo,team,B\ninfo,date,2008/04/18\n"), wantErr: ErrMissingRegistry},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data, err := decodeCsvMatch(balls, test.info)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("decodeCsvMatch error = %v, want %v", err, test.wantErr)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			if len(data.Innings) != 1 || data.Innings[0].Over[0].Deliveries[0].Extras.LegByes != 1 {
+				t.Errorf("innings = %+v, want one innings with a leg bye", data.Innings)
+			}
+		})
+	}
+}
+
+func TestPairCsvInfoFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"335982.csv":      "balls",
+		"335982_info.csv": "info",
+		"335983.csv":      "unpaired",
+		"335984.json":     "{}",
+	})
+	archivePath := filepath.Join(dir, "ipl_csv2.zip")
+	writeZip(t, archivePath, map[string]string{
+		"ipl_csv2/335985.csv":      "archive balls",
+		"ipl_csv2/335985_info.csv": "archive info",
+		"ipl_csv2/README.txt":      "readme",
+	})
+
+	files, closeArchives, err := IngestConfig{Inputs: []string{dir + "/*.csv", dir + "/*.json", archivePath}}.matchFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeArchives()
+
+	want := map[string]string{
+		filepath.Join(dir, "335982.csv"):     "info",
+		filepath.Join(dir, "335983.csv"):     "",
+		filepath.Join(dir, "335984.json"):    "",
+		archivePath + "/ipl_csv2/335985.csv": "archive info",
+		archivePath + "/ipl_csv2/README.txt": "",
+	}
+	paths := matchFilePaths(files)
+	if len(paths) != len(want) {
+		t.Fatalf("match files = %v, want %d files without the info files", paths, len(want))
+	}
+	for _, file := range files {
+		info, ok := want[file.path]
+		if !ok {
+			t.Errorf("unexpected match file %s", file.path)
+			continue
+		}
+		if info == "" {
+			if file.readInfo != nil {
+				t.Errorf("info file of %s is set, want none", file.path)
+			}
+			continue
+		}
+		if file.readInfo == nil {
+			t.Errorf("info file of %s is not set", file.path)
+			continue
+		}
+		content, err := file.readInfo()
+		if err != nil || string(content) != info {
+			t.Errorf("info of %s = %q, %v, want %q", file.path, content, err, info)
+		}
+	}
+}
+
+func TestCsvInfoPath(t *testing.T) {
+	if got := csvInfoPath("ipl_csv2.zip/335982.csv"); got != "ipl_csv2.zip/335982_info.csv" {
+		t.Errorf("csvInfoPath = %s", got)
+	}
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// ballsPerOver cricsheet has balls_per_over in info, but it is not stored in event. all the
+// formats stored currently have six ball overs.
+const ballsPerOver = 6
+
+func roundTwoDecimals(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// strikeRate runs scored per 100 balls
+func strikeRate(runs int, balls int) float64 {
+	if balls == 0 {
+		return 0
+	}
+	return roundTwoDecimals(float64(runs) * 100 / float64(balls))
+}
+
+// oversText converts balls into cricket notation, eg: 123 balls is 20.3 overs
+func oversText(balls int) string {
+	return fmt.Sprintf("%d.%d", balls/ballsPerOver, balls%ballsPerOver)
+}
+
+// economy runs conceded per over
+func economy(runs int, balls int) float64 {
+	if balls == 0 {
+		return 0
+	}
+	return roundTwoDecimals(float64(runs) * ballsPerOver / float64(balls))
+}
+
+// average runs per dismissal. zero when the player is never dismissed.
+func average(runs int, dismissals int) float64 {
+	if dismissals == 0 {
+		return 0
+	}
+	return roundTwoDecimals(float64(runs) / float64(dismissals))
+}
+
+func percentage(count int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return roundTwoDecimals(float64(count) * 100 / float64(total))
+}
+
+// oversToBalls converts cricket notation into balls, eg: 17.3 overs is 105 balls
+func oversToBalls(overs float64) int {
+	completedOvers := int(overs)
+	return completedOvers*ballsPerOver + int(math.Round((overs-float64(completedOvers))*10))
+}
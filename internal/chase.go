@@ -0,0 +1,230 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrChaseNotFound = errors.New("match does not have a chase")
+
+// highestChasesLimit number of successful chases listed in the chase analysis
+const highestChasesLimit = 10
+
+type ChaseBall struct {
+	Over            string  `json:"over"`
+	Score           int     `json:"score"`
+	Wickets         int     `json:"wickets"`
+	RunsRequired    int     `json:"runs_required"`
+	BallsRemaining  int     `json:"balls_remaining"`
+	CurrentRunRate  float64 `json:"current_run_rate"`
+	RequiredRunRate float64 `json:"required_run_rate"`
+}
+
+type ChaseResponse struct {
+	MatchId       int         `json:"match_id"`
+	Team          string      `json:"team"`
+	TargetRuns    int         `json:"target_runs"`
+	TargetOvers   float64     `json:"target_overs"`
+	TargetRevised bool        `json:"target_revised"`
+	Result        string      `json:"result"`
+	Balls         []ChaseBall `json:"balls"`
+}
+
+type ChaseBand struct {
+	From        int     `db:"band_from" json:"from"`
+	To          int     `db:"-" json:"to"`
+	Chases      int     `db:"chases" json:"chases"`
+	Successful  int     `db:"successful" json:"successful"`
+	SuccessRate float64 `db:"-" json:"success_rate"`
+}
+
+type SuccessfulChase struct {
+	MatchId       int    `db:"match_id" json:"match_id"`
+	Team          string `db:"team" json:"team"`
+	Opponent      string `db:"opponent" json:"opponent"`
+	TargetRuns    int    `db:"target_runs" json:"target_runs"`
+	TargetRevised bool   `db:"target_revised" json:"target_revised"`
+	Date          string `db:"date" json:"date"`
+	Link          string `db:"-" json:"link"`
+}
+
+type ChaseAnalysisResponse struct {
+	Bands                   []ChaseBand       `json:"bands"`
+	HighestSuccessfulChases []SuccessfulChase `json:"highest_successful_chases"`
+}
+
+type chaseInnings struct {
+	ID            int
+	Team          string
+	TargetRuns    int
+	TargetOvers   float64
+	TargetRevised bool
+}
+
+type chaseBallRow struct {
+	OverLabel string `db:"over_label"`
+	Runs      int    `db:"runs"`
+	IsLegal   bool   `db:"is_legal"`
+	Wickets   int    `db:"wickets"`
+}
+
+// getChaseInnings the innings chasing a target in the match, super overs are not considered
+func getChaseInnings(eventId int, dbPool *pgxpool.Pool) (chaseInnings, error) {
+	sqlQuery := `
+			SELECT i.id, t.name, i.target_runs, i.target_overs, i.target_revised
+			FROM innings AS i JOIN team AS t ON t.id = i.batting_team
+			WHERE i.event = @event AND i.target_runs IS NOT NULL AND NOT i.super_over
+			ORDER BY i.ordinal
+			LIMIT 1`
+	namedArgs := pgx.NamedArgs{"event": eventId}
+
+	var innings chaseInnings
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(
+		&innings.ID, &innings.Team, &innings.TargetRuns, &innings.TargetOvers, &innings.TargetRevised)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return chaseInnings{}, ErrChaseNotFound
+	}
+	if err != nil {
+		return chaseInnings{}, err
+	}
+	return innings, nil
+}
+
+func chaseBalls(inningsId int, dbPool *pgxpool.Pool) ([]chaseBallRow, error) {
+	sqlQuery := fmt.Sprintf(`
+			SELECT
+			    bi.over_label AS over_label,
+			    bi.striker_run + bi.extra_run AS runs,
+			    bi.is_legal AS is_legal,
+			    COALESCE(bw.wickets, 0) AS wickets
+			FROM ball_info AS bi
+			    LEFT JOIN (%s) AS bw ON bw.ball_info = bi.id
+			WHERE bi.innings = @innings
+			ORDER BY bi.id`, ballWicketsQuery)
+	namedArgs := pgx.NamedArgs{"innings": inningsId}
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[chaseBallRow])
+}
+
+// QueryChase ball by ball progress of the chase with the required run rate after every ball
+func QueryChase(matchId int, appInstance *app.App) (ChaseResponse, error) {
+	event, err := getMatchEvent(matchId, appInstance.DB)
+	if err != nil {
+		return ChaseResponse{}, err
+	}
+	innings, err := getChaseInnings(event.ID, appInstance.DB)
+	if err != nil {
+		return ChaseResponse{}, err
+	}
+	balls, err := chaseBalls(innings.ID, appInstance.DB)
+	if err != nil {
+		return ChaseResponse{}, err
+	}
+
+	response := ChaseResponse{
+		MatchId:       event.MatchId,
+		Team:          innings.Team,
+		TargetRuns:    innings.TargetRuns,
+		TargetOvers:   innings.TargetOvers,
+		TargetRevised: innings.TargetRevised,
+		Result:        event.Result,
+		Balls:         make([]ChaseBall, 0, len(balls)),
+	}
+	totalBalls := oversToBalls(innings.TargetOvers)
+	score, wickets, legalBalls := 0, 0, 0
+	for _, ball := range balls {
+		score += ball.Runs
+		wickets += ball.Wickets
+		if ball.IsLegal {
+			legalBalls += 1
+		}
+		runsRequired := max(innings.TargetRuns-score, 0)
+		ballsRemaining := max(totalBalls-legalBalls, 0)
+
+		chaseBall := ChaseBall{
+			Over:           ball.OverLabel,
+			Score:          score,
+			Wickets:        wickets,
+			RunsRequired:   runsRequired,
+			BallsRemaining: ballsRemaining,
+			CurrentRunRate: economy(score, legalBalls),
+		}
+		if ballsRemaining > 0 {
+			chaseBall.RequiredRunRate = economy(runsRequired, ballsRemaining)
+		}
+		response.Balls = append(response.Balls, chaseBall)
+	}
+	return response, nil
+}
+
+// QueryChaseAnalysis success rate of chases grouped by target in bands of bandSize runs, along with
+// the highest successful chases. matches without result are not counted.
+func QueryChaseAnalysis(filter StatsFilter, bandSize int, appInstance *app.App) (ChaseAnalysisResponse, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	namedArgs["band_size"] = bandSize
+	namedArgs["limit"] = highestChasesLimit
+
+	bandsQuery := fmt.Sprintf(`
+			SELECT
+			    (i.target_runs / @band_size) * @band_size AS band_from,
+			    COUNT(*) AS chases,
+			    COUNT(CASE WHEN r.team_won = i.batting_team THEN 1 END) AS successful
+			FROM innings AS i
+			    JOIN event AS e ON e.id = i.event AND %s
+			    JOIN end_result AS r ON r.event = e.id
+			WHERE i.target_runs IS NOT NULL AND NOT i.super_over AND r.result <> 'no result'
+			GROUP BY band_from
+			ORDER BY band_from`, condition)
+	rows, err := appInstance.DB.Query(context.TODO(), bandsQuery, namedArgs)
+	if err != nil {
+		return ChaseAnalysisResponse{}, err
+	}
+	bands, err := pgx.CollectRows(rows, pgx.RowToStructByName[ChaseBand])
+	if err != nil {
+		return ChaseAnalysisResponse{}, err
+	}
+	for i := range bands {
+		bands[i].To = bands[i].From + bandSize - 1
+		bands[i].SuccessRate = percentage(bands[i].Successful, bands[i].Chases)
+	}
+
+	chasesQuery := fmt.Sprintf(`
+			SELECT
+			    e.file_id AS match_id,
+			    t.name AS team,
+			    o.name AS opponent,
+			    i.target_runs AS target_runs,
+			    i.target_revised AS target_revised,
+			    TO_CHAR(e.date, 'YYYY-MM-DD') AS date
+			FROM innings AS i
+			    JOIN event AS e ON e.id = i.event AND %s
+			    JOIN end_result AS r ON r.event = e.id AND r.team_won = i.batting_team
+			    JOIN team AS t ON t.id = i.batting_team
+			    JOIN team AS o ON o.id = CASE WHEN e.team_a = i.batting_team THEN e.team_b ELSE e.team_a END
+			WHERE i.target_runs IS NOT NULL AND NOT i.super_over
+			ORDER BY i.target_runs DESC, e.date
+			LIMIT @limit`, condition)
+	rows, err = appInstance.DB.Query(context.TODO(), chasesQuery, namedArgs)
+	if err != nil {
+		return ChaseAnalysisResponse{}, err
+	}
+	chases, err := pgx.CollectRows(rows, pgx.RowToStructByName[SuccessfulChase])
+	if err != nil {
+		return ChaseAnalysisResponse{}, err
+	}
+	for i := range chases {
+		chases[i].Link = fmt.Sprintf("/v1/cricket/match/%d/scorecard", chases[i].MatchId)
+	}
+
+	return ChaseAnalysisResponse{Bands: bands, HighestSuccessfulChases: chases}, nil
+}
@@ -0,0 +1,3 @@
+ALTER TABLE event DROP COLUMN season;
+ALTER TABLE event DROP COLUMN gender;
+ALTER TABLE event DROP COLUMN team_type;
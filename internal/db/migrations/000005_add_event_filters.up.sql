@@ -0,0 +1,3 @@
+ALTER TABLE event ADD COLUMN season VARCHAR(20);
+ALTER TABLE event ADD COLUMN gender VARCHAR(20);
+ALTER TABLE event ADD COLUMN team_type VARCHAR(20);
@@ -0,0 +1,2 @@
+ALTER TABLE ball_info DROP COLUMN wides;
+ALTER TABLE ball_info DROP COLUMN noballs;
@@ -0,0 +1,2 @@
+ALTER TABLE ball_info ADD COLUMN wides INT NOT NULL DEFAULT 0;
+ALTER TABLE ball_info ADD COLUMN noballs INT NOT NULL DEFAULT 0;
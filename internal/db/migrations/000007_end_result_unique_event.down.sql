@@ -0,0 +1 @@
+ALTER TABLE end_result DROP CONSTRAINT end_result_event_key;
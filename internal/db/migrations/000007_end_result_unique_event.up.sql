@@ -0,0 +1 @@
+ALTER TABLE end_result ADD CONSTRAINT end_result_event_key UNIQUE (event);
@@ -0,0 +1,9 @@
+ALTER TABLE end_result DROP COLUMN summary;
+ALTER TABLE end_result DROP COLUMN win_by_runs;
+ALTER TABLE end_result DROP COLUMN win_by_wickets;
+ALTER TABLE end_result DROP COLUMN win_by_innings;
+ALTER TABLE end_result DROP COLUMN method;
+ALTER TABLE end_result DROP COLUMN eliminator;
+ALTER TABLE end_result DROP COLUMN bowl_out;
+ALTER TABLE end_result DROP COLUMN team_a_wickets;
+ALTER TABLE end_result DROP COLUMN team_b_wickets;
@@ -0,0 +1,11 @@
+ALTER TABLE end_result ADD COLUMN summary VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE end_result ADD COLUMN win_by_runs INT NOT NULL DEFAULT 0;
+ALTER TABLE end_result ADD COLUMN win_by_wickets INT NOT NULL DEFAULT 0;
+ALTER TABLE end_result ADD COLUMN win_by_innings INT NOT NULL DEFAULT 0;
+ALTER TABLE end_result ADD COLUMN method VARCHAR(50) NOT NULL DEFAULT '';
+ALTER TABLE end_result ADD COLUMN eliminator INT;
+ALTER TABLE end_result ADD CONSTRAINT fk_eliminator FOREIGN KEY (eliminator) REFERENCES team(id) ON DELETE SET NULL;
+ALTER TABLE end_result ADD COLUMN bowl_out INT;
+ALTER TABLE end_result ADD CONSTRAINT fk_bowl_out FOREIGN KEY (bowl_out) REFERENCES team(id) ON DELETE SET NULL;
+ALTER TABLE end_result ADD COLUMN team_a_wickets INT NOT NULL DEFAULT 0;
+ALTER TABLE end_result ADD COLUMN team_b_wickets INT NOT NULL DEFAULT 0;
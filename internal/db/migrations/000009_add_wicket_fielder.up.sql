@@ -0,0 +1,11 @@
+CREATE TABLE wicket_fielder (
+    id serial PRIMARY KEY,
+    wicket int NOT NULL, CONSTRAINT fk_wicket FOREIGN KEY (wicket) REFERENCES wicket(id) ON DELETE CASCADE,
+    player int, CONSTRAINT fk_player FOREIGN KEY (player) REFERENCES player(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    substitute BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX wicket_fielder_player_idx ON wicket_fielder (player);
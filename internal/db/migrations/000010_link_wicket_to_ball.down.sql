@@ -0,0 +1,10 @@
+ALTER TABLE ball_info ADD COLUMN wicket int;
+ALTER TABLE ball_info ADD CONSTRAINT fk_wicket FOREIGN KEY (wicket) REFERENCES wicket(id) ON DELETE SET NULL;
+
+-- only one wicket per ball can be linked back
+UPDATE ball_info SET wicket = w.id FROM (
+    SELECT ball_info, MIN(id) AS id FROM wicket GROUP BY ball_info
+) AS w WHERE w.ball_info = ball_info.id;
+
+DROP INDEX IF EXISTS wicket_ball_info_idx;
+ALTER TABLE wicket DROP COLUMN ball_info;
@@ -0,0 +1,7 @@
+ALTER TABLE wicket ADD COLUMN ball_info int;
+ALTER TABLE wicket ADD CONSTRAINT fk_ball_info FOREIGN KEY (ball_info) REFERENCES ball_info(id) ON DELETE CASCADE;
+
+UPDATE wicket SET ball_info = bi.id FROM ball_info AS bi WHERE bi.wicket = wicket.id;
+CREATE INDEX wicket_ball_info_idx ON wicket (ball_info);
+
+ALTER TABLE ball_info DROP COLUMN wicket;
@@ -0,0 +1,3 @@
+ALTER TABLE ball_info DROP COLUMN byes;
+ALTER TABLE ball_info DROP COLUMN legbyes;
+ALTER TABLE ball_info DROP COLUMN penalty;
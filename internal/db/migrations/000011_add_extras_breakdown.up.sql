@@ -0,0 +1,3 @@
+ALTER TABLE ball_info ADD COLUMN byes INT NOT NULL DEFAULT 0;
+ALTER TABLE ball_info ADD COLUMN legbyes INT NOT NULL DEFAULT 0;
+ALTER TABLE ball_info ADD COLUMN penalty INT NOT NULL DEFAULT 0;
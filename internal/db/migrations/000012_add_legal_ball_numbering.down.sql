@@ -0,0 +1,4 @@
+ALTER TABLE ball_info DROP COLUMN delivery;
+ALTER TABLE ball_info DROP COLUMN legal_ball;
+ALTER TABLE ball_info DROP COLUMN is_legal;
+ALTER TABLE ball_info DROP COLUMN over_label;
@@ -0,0 +1,22 @@
+ALTER TABLE ball_info ADD COLUMN delivery INT NOT NULL DEFAULT 0;
+ALTER TABLE ball_info ADD COLUMN legal_ball INT NOT NULL DEFAULT 0;
+ALTER TABLE ball_info ADD COLUMN is_legal BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE ball_info ADD COLUMN over_label VARCHAR(10) NOT NULL DEFAULT '';
+
+-- wides and no-balls carry the number of the next legal ball, eg: a wide before 14.3 is labelled 14.3
+UPDATE ball_info SET
+    delivery = numbered.delivery,
+    legal_ball = numbered.legal_ball,
+    is_legal = numbered.is_legal,
+    over_label = numbered.over || '.' || numbered.legal_ball
+FROM (
+    SELECT
+        id,
+        over,
+        wides = 0 AND noballs = 0 AS is_legal,
+        ROW_NUMBER() OVER (PARTITION BY event, batting_team ORDER BY id) AS delivery,
+        COUNT(CASE WHEN wides = 0 AND noballs = 0 THEN 1 END) OVER (PARTITION BY event, batting_team, over ORDER BY id)
+            + CASE WHEN wides = 0 AND noballs = 0 THEN 0 ELSE 1 END AS legal_ball
+    FROM ball_info
+) AS numbered
+WHERE numbered.id = ball_info.id;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS ball_info_innings_idx;
+ALTER TABLE ball_info DROP COLUMN innings;
+DROP TABLE IF EXISTS innings;
@@ -0,0 +1,28 @@
+CREATE TABLE innings (
+    id serial PRIMARY KEY,
+    event int NOT NULL, CONSTRAINT fk_event FOREIGN KEY (event) REFERENCES event(id) ON DELETE CASCADE,
+    ordinal int NOT NULL,
+    batting_team int, CONSTRAINT fk_batting_team FOREIGN KEY (batting_team) REFERENCES team(id) ON DELETE SET NULL,
+    target_runs int,
+    target_overs NUMERIC(5, 1),
+    declared BOOLEAN NOT NULL DEFAULT FALSE,
+    forfeited BOOLEAN NOT NULL DEFAULT FALSE,
+    super_over BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT innings_event_ordinal_key UNIQUE (event, ordinal)
+);
+
+ALTER TABLE ball_info ADD COLUMN innings int;
+ALTER TABLE ball_info ADD CONSTRAINT fk_innings FOREIGN KEY (innings) REFERENCES innings(id) ON DELETE CASCADE;
+CREATE INDEX ball_info_innings_idx ON ball_info (innings);
+
+-- already stored balls do not have innings information, each batting team of the match is taken as an innings
+INSERT INTO innings (event, ordinal, batting_team)
+SELECT event, ROW_NUMBER() OVER (PARTITION BY event ORDER BY MIN(id)), batting_team
+FROM ball_info
+GROUP BY event, batting_team;
+
+UPDATE ball_info SET innings = i.id
+FROM innings AS i
+WHERE i.event = ball_info.event AND i.batting_team = ball_info.batting_team;
@@ -0,0 +1,11 @@
+CREATE TABLE powerplay (
+    id serial PRIMARY KEY,
+    innings int NOT NULL, CONSTRAINT fk_innings FOREIGN KEY (innings) REFERENCES innings(id) ON DELETE CASCADE,
+    from_over NUMERIC(4, 1) NOT NULL,
+    to_over NUMERIC(4, 1) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX powerplay_innings_idx ON powerplay (innings);
@@ -0,0 +1 @@
+ALTER TABLE innings DROP COLUMN target_revised;
@@ -0,0 +1 @@
+ALTER TABLE innings ADD COLUMN target_revised BOOLEAN NOT NULL DEFAULT FALSE;
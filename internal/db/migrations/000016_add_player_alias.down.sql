@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS match_player;
+DROP TABLE IF EXISTS player_alias;
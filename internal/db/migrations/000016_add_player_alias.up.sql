@@ -0,0 +1,31 @@
+CREATE TABLE player_alias (
+    id serial PRIMARY KEY,
+    player int NOT NULL, CONSTRAINT fk_player FOREIGN KEY (player) REFERENCES player(id) ON DELETE CASCADE,
+    source_id VARCHAR(15) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT player_alias_source_id_name_key UNIQUE (source_id, name)
+);
+
+CREATE INDEX player_alias_player_idx ON player_alias (player);
+
+CREATE TABLE match_player (
+    event int NOT NULL, CONSTRAINT fk_event FOREIGN KEY (event) REFERENCES event(id) ON DELETE CASCADE,
+    player int NOT NULL, CONSTRAINT fk_player FOREIGN KEY (player) REFERENCES player(id) ON DELETE CASCADE,
+    source_id VARCHAR(15) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (event, name)
+);
+
+CREATE INDEX match_player_player_idx ON match_player (player);
+
+INSERT INTO player_alias (player, source_id, name)
+SELECT id, source_id, name FROM player WHERE source_id IS NOT NULL;
+
+-- registry of the older matches is not stored, playing 11 is the best available mapping for them
+INSERT INTO match_player (event, player, source_id, name)
+SELECT e.id, p.id, p.source_id, p.name
+FROM event AS e JOIN player AS p ON p.id = ANY(e.playing_11_a_ids || e.playing_11_b_ids)
+WHERE p.source_id IS NOT NULL
+ON CONFLICT DO NOTHING;
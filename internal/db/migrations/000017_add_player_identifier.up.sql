@@ -0,0 +1,10 @@
+CREATE TABLE player_identifier (
+    source_id VARCHAR(15) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    identifier VARCHAR(100) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (source_id, provider, identifier)
+);
+
+CREATE INDEX player_identifier_provider_identifier_idx ON player_identifier (provider, identifier);
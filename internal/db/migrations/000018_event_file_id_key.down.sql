@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS event_match_type_match_id_idx;
+ALTER TABLE event DROP CONSTRAINT IF EXISTS event_file_id_key;
+UPDATE event SET match_id = file_id WHERE match_id IS NULL;
+ALTER TABLE event ALTER COLUMN match_id SET NOT NULL;
+ALTER TABLE event ADD CONSTRAINT event_match_id_key UNIQUE (match_id);
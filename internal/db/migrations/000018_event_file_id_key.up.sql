@@ -0,0 +1,10 @@
+-- events were identified by match_id (match_type_number of the file), which repeats across match types,
+-- eg: ODI 1234 and Test 1234, and is missing for domestic matches. events are identified by the cricsheet
+-- file id from now on, the id in the file name and in the match urls of cricsheet.
+-- existing rows keep their match_id, except the domestic matches where it was filled with the file id.
+-- match ids of the scorecard and chase api are file ids after this migration.
+ALTER TABLE event DROP CONSTRAINT IF EXISTS event_match_id_key;
+ALTER TABLE event ALTER COLUMN match_id DROP NOT NULL;
+UPDATE event SET match_id = NULL WHERE match_id = file_id;
+ALTER TABLE event ADD CONSTRAINT event_file_id_key UNIQUE (file_id);
+CREATE INDEX IF NOT EXISTS event_match_type_match_id_idx ON event (match_type, match_id);
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type FieldingResponse struct {
+	PlayerId      int    `db:"player_id" json:"player_id"`
+	Name          string `db:"name" json:"name"`
+	Catches       int    `db:"catches" json:"catches"`
+	KeeperCatches int    `db:"keeper_catches" json:"keeper_catches"`
+	Stumpings     int    `db:"stumpings" json:"stumpings"`
+	RunOuts       int    `db:"run_outs" json:"run_outs"`
+	Dismissals    int    `db:"dismissals" json:"dismissals"`
+}
+
+type FieldingLeaderboardResponse struct {
+	Players  []FieldingResponse `json:"players"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+}
+
+// fieldingQuery aggregates the dismissals made by each fielder. caught and bowled is credited as
+// a catch to the bowler. cricsheet does not mark the wicket keeper, so a player who has made a stumping
+// in any match of the match type and season is considered as the keeper in all the matches of that
+// match type and season, and their catches are keeper catches. stumpings are rare, so deciding it
+// per match would miss the keeper in most matches. a player who has kept only in some of the
+// matches of a season has all the catches of the season as keeper catches.
+const fieldingQuery = `
+			WITH fielding AS (
+			    SELECT w.event, wf.player, w.kind
+			    FROM wicket_fielder AS wf JOIN wicket AS w ON w.id = wf.wicket
+			    WHERE wf.player IS NOT NULL AND w.kind <> 'caught and bowled' AND %[1]s
+			    UNION ALL
+			    SELECT w.event, w.bowler, w.kind FROM wicket AS w WHERE w.kind = 'caught and bowled' AND %[1]s
+			), keepers AS (
+			    SELECT DISTINCT wf.player, ke.match_type, ke.season
+			    FROM wicket_fielder AS wf
+			        JOIN wicket AS kw ON kw.id = wf.wicket AND kw.kind = 'stumped'
+			        JOIN event AS ke ON ke.id = kw.event
+			    WHERE wf.player IS NOT NULL
+			)
+			SELECT
+			    f.player AS player_id,
+			    p.name AS name,
+			    COUNT(CASE WHEN f.kind IN ('caught', 'caught and bowled') AND k.player IS NULL THEN 1 END) AS catches,
+			    COUNT(CASE WHEN f.kind = 'caught' AND k.player IS NOT NULL THEN 1 END) AS keeper_catches,
+			    COUNT(CASE WHEN f.kind = 'stumped' THEN 1 END) AS stumpings,
+			    COUNT(CASE WHEN f.kind = 'run out' THEN 1 END) AS run_outs,
+			    COUNT(*) AS dismissals
+			FROM fielding AS f
+			    JOIN event AS e ON e.id = f.event AND %[2]s
+			    JOIN player AS p ON p.id = f.player
+			    LEFT JOIN keepers AS k
+			        ON k.player = f.player AND k.match_type = e.match_type AND k.season IS NOT DISTINCT FROM e.season
+			WHERE %[3]s
+			GROUP BY f.player, p.name`
+
+// QueryPlayerFielding fielding stats of the player
+func QueryPlayerFielding(playerId int, filter StatsFilter, appInstance *app.App) (FieldingResponse, error) {
+	name, err := getPlayerName(playerId, appInstance.DB)
+	if err != nil {
+		return FieldingResponse{}, err
+	}
+
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(fieldingQuery, filter.wicketCondition("w"), condition, "f.player = @player")
+	namedArgs["player"] = playerId
+
+	rows, err := appInstance.DB.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return FieldingResponse{}, err
+	}
+	stats, err := pgx.CollectRows(rows, pgx.RowToStructByName[FieldingResponse])
+	if err != nil {
+		return FieldingResponse{}, err
+	}
+	if len(stats) == 0 {
+		// player has not been part of any dismissal
+		return FieldingResponse{PlayerId: playerId, Name: name}, nil
+	}
+	return stats[0], nil
+}
+
+// QueryFieldingLeaderboard fielders ordered by the dismissals they have been part of
+func QueryFieldingLeaderboard(
+	filter StatsFilter, pagination Pagination, appInstance *app.App,
+) (FieldingLeaderboardResponse, error) {
+	pagination = pagination.normalise()
+
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(fieldingQuery, filter.wicketCondition("w"), condition, "TRUE") + `
+			ORDER BY dismissals DESC, name
+			LIMIT @limit OFFSET @offset`
+	namedArgs["limit"] = pagination.PageSize
+	namedArgs["offset"] = pagination.offset()
+
+	rows, err := appInstance.DB.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return FieldingLeaderboardResponse{}, err
+	}
+	players, err := pgx.CollectRows(rows, pgx.RowToStructByName[FieldingResponse])
+	if err != nil {
+		return FieldingLeaderboardResponse{}, err
+	}
+	return FieldingLeaderboardResponse{
+		Players:  players,
+		Page:     pagination.Page,
+		PageSize: pagination.PageSize,
+	}, nil
+}
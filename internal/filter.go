@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// StatsFilter narrows down the events considered by the stats queries.
+// empty fields are not applied.
+type StatsFilter struct {
+	MatchType string
+	Season    string
+	FromDate  time.Time
+	ToDate    time.Time
+	Team      string
+	Venue     string
+	Gender    string
+	TeamType  string
+	EventName string
+	// IncludeSuperOvers super over innings are left out of the aggregates unless this is set
+	IncludeSuperOvers bool
+}
+
+// eventCondition returns the where condition on the event table referred with the given alias
+// along with the named args used in it. condition is "TRUE" when no filter is set,
+// so it can always be added to the query.
+func (filter StatsFilter) eventCondition(alias string) (string, pgx.NamedArgs) {
+	conditions := make([]string, 0)
+	namedArgs := pgx.NamedArgs{}
+
+	if filter.MatchType != "" {
+		conditions = append(conditions, alias+".match_type = @match_type")
+		namedArgs["match_type"] = filter.MatchType
+	}
+	if filter.Season != "" {
+		conditions = append(conditions, alias+".season = @season")
+		namedArgs["season"] = filter.Season
+	}
+	if !filter.FromDate.IsZero() {
+		conditions = append(conditions, alias+".date >= @from_date")
+		namedArgs["from_date"] = filter.FromDate
+	}
+	if !filter.ToDate.IsZero() {
+		conditions = append(conditions, alias+".date <= @to_date")
+		namedArgs["to_date"] = filter.ToDate
+	}
+	if filter.Team != "" {
+		conditions = append(
+			conditions,
+			"EXISTS(SELECT 1 FROM team AS ft WHERE ft.name = @team AND ft.id IN ("+alias+".team_a, "+alias+".team_b))",
+		)
+		namedArgs["team"] = filter.Team
+	}
+	if filter.Venue != "" {
+		// venue names contain the city as well, eg: Wankhede Stadium, Mumbai
+		conditions = append(conditions, alias+".venue ILIKE '%' || @venue || '%'")
+		namedArgs["venue"] = filter.Venue
+	}
+	if filter.Gender != "" {
+		conditions = append(conditions, alias+".gender = @gender")
+		namedArgs["gender"] = filter.Gender
+	}
+	if filter.TeamType != "" {
+		conditions = append(conditions, alias+".team_type = @team_type")
+		namedArgs["team_type"] = filter.TeamType
+	}
+	if filter.EventName != "" {
+		conditions = append(conditions, alias+".name ILIKE '%' || @event_name || '%'")
+		namedArgs["event_name"] = filter.EventName
+	}
+
+	if len(conditions) == 0 {
+		return "TRUE", namedArgs
+	}
+	return strings.Join(conditions, " AND "), namedArgs
+}
+
+// ballCondition where condition on the ball_info table referred with the given alias
+func (filter StatsFilter) ballCondition(alias string) string {
+	if filter.IncludeSuperOvers {
+		return "TRUE"
+	}
+	return "NOT EXISTS(SELECT 1 FROM innings AS si WHERE si.id = " + alias + ".innings AND si.super_over)"
+}
+
+// wicketCondition where condition on the wicket table referred with the given alias
+func (filter StatsFilter) wicketCondition(alias string) string {
+	if filter.IncludeSuperOvers {
+		return "TRUE"
+	}
+	return "NOT EXISTS(" +
+		"SELECT 1 FROM ball_info AS sb JOIN innings AS si ON si.id = sb.innings " +
+		"WHERE sb.id = " + alias + ".ball_info AND si.super_over)"
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Pagination page starts from 1
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+// normalise fills the defaults for missing or out of range values
+func (pagination Pagination) normalise() Pagination {
+	if pagination.Page < 1 {
+		pagination.Page = 1
+	}
+	if pagination.PageSize < 1 {
+		pagination.PageSize = defaultPageSize
+	}
+	if pagination.PageSize > maxPageSize {
+		pagination.PageSize = maxPageSize
+	}
+	return pagination
+}
+
+func (pagination Pagination) offset() int {
+	return (pagination.Page - 1) * pagination.PageSize
+}
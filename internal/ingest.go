@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"archive/zip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	jsonparser "cricket/pkg/json_parser"
+)
+
+// DefaultInput directory of match files read when no input is given
+const DefaultInput = "t20s_male_json"
+
+var (
+	ErrNoMatchFiles   = errors.New("no match files in the given inputs")
+	ErrInvalidFileId  = errors.New("match file name should be the cricsheet id of the match, eg: 1234.json")
+	ErrIncompleteInfo = errors.New("match info should have two teams and the date of the match")
+	ErrEventExists    = errors.New("event of the match is already saved")
+)
+
+// IngestConfig input directories and filters of the ingestion. empty filters are not applied.
+// inputs are directories, zip archives like t20s_male_json.zip or glob patterns like odis_json/*.json.
+// match files can be json, yaml or csv along with their _info.csv file.
+// match types and genders are matched with the info of the file, eg: T20, ODI, Test and male, female.
+type IngestConfig struct {
+	Inputs            []string
+	IncludeMatchTypes []string
+	ExcludeMatchTypes []string
+	IncludeGenders    []string
+	ExcludeGenders    []string
+	// Limit maximum number of matches ingested in the run, 0 is no limit
+	Limit int
+	// Workers number of matches ingested in parallel, 1 when not set
+	Workers int
+}
+
+type ingestStatus string
+
+const (
+	statusIngested ingestStatus = "ingested"
+	// statusSkipped file could not be read as a match
+	statusSkipped  ingestStatus = "skipped"
+	statusFiltered ingestStatus = "filtered"
+	// statusExists match is already saved or is being saved from another file
+	statusExists  ingestStatus = "exists"
+	statusLimit   ingestStatus = "limit reached"
+	statusIgnored ingestStatus = "ignored"
+	statusFailed  ingestStatus = "failed"
+)
+
+type ingestResult struct {
+	path   string
+	fileId int
+	status ingestStatus
+	err    error
+}
+
+// ingestState shared by the workers of a run. a match is claimed by a worker before saving it,
+// so that the same match from two files is not saved twice and the limit is not crossed.
+type ingestState struct {
+	config  IngestConfig
+	mutex   sync.Mutex
+	claimed map[int]bool
+}
+
+func newIngestState(config IngestConfig) *ingestState {
+	return &ingestState{config: config, claimed: make(map[int]bool)}
+}
+
+// claim returns empty status when the worker can save the match with the file id
+func (state *ingestState) claim(fileId int) ingestStatus {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	if state.claimed[fileId] {
+		return statusExists
+	}
+	if state.config.Limit > 0 && len(state.claimed) >= state.config.Limit {
+		return statusLimit
+	}
+	state.claimed[fileId] = true
+	return ""
+}
+
+func (state *ingestState) limitReached() bool {
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+	return state.config.Limit > 0 && len(state.claimed) >= state.config.Limit
+}
+
+// matchFile a match file on disk or inside a zip archive. path of a file inside
+// an archive is prefixed with the archive path, eg: t20s_male_json.zip/1234.json.
+// readInfo is set for csv match files which have the match info in a separate file.
+type matchFile struct {
+	path     string
+	read     func() ([]byte, error)
+	readInfo func() ([]byte, error)
+}
+
+// matchFiles files of the inputs, in the order of inputs and sorted by name within an input.
+// a file matched by more than one input is returned once. the returned function closes the
+// zip archives opened for reading, call it after reading the files.
+func (config IngestConfig) matchFiles() ([]matchFile, func(), error) {
+	inputs := config.Inputs
+	if len(inputs) == 0 {
+		inputs = []string{DefaultInput}
+	}
+
+	archives := make([]*zip.ReadCloser, 0)
+	closeArchives := func() {
+		for _, archive := range archives {
+			_ = archive.Close()
+		}
+	}
+
+	files := make([]matchFile, 0)
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		paths, err := inputFiles(input)
+		if err != nil {
+			closeArchives()
+			return nil, nil, err
+		}
+		for _, path := range paths {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if !isZipArchive(path) {
+				files = append(files, diskFile(path))
+				continue
+			}
+
+			archive, err := zip.OpenReader(path)
+			if err != nil {
+				closeArchives()
+				return nil, nil, err
+			}
+			archives = append(archives, archive)
+			files = append(files, archiveFiles(path, archive)...)
+		}
+	}
+	files = pairCsvInfoFiles(files)
+	if len(files) == 0 {
+		closeArchives()
+		return nil, nil, ErrNoMatchFiles
+	}
+	return files, closeArchives, nil
+}
+
+// pairCsvInfoFiles sets the info file of every csv match file, info files are not returned as match files
+func pairCsvInfoFiles(files []matchFile) []matchFile {
+	infoFiles := make(map[string]matchFile)
+	for _, file := range files {
+		if isCsvInfoFile(file.path) {
+			infoFiles[file.path] = file
+		}
+	}
+	if len(infoFiles) == 0 {
+		return files
+	}
+
+	paired := make([]matchFile, 0, len(files)-len(infoFiles))
+	for _, file := range files {
+		if isCsvInfoFile(file.path) {
+			continue
+		}
+		if info, ok := infoFiles[csvInfoPath(file.path)]; ok && isCsvFile(file.path) {
+			file.readInfo = info.read
+		}
+		paired = append(paired, file)
+	}
+	return paired
+}
+
+// matchFileId cricsheet id of the match from the name of the file, eg: 1234 of odis_json.zip/1234.json
+func matchFileId(path string) (int, error) {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	fileId, err := strconv.Atoi(name)
+	if err != nil || fileId <= 0 {
+		return 0, ErrInvalidFileId
+	}
+	return fileId, nil
+}
+
+func isZipArchive(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".zip")
+}
+
+func diskFile(path string) matchFile {
+	return matchFile{
+		path: path,
+		read: func() ([]byte, error) {
+			return os.ReadFile(path)
+		},
+	}
+}
+
+// archiveFiles files of the zip archive sorted by name, directories inside the archive are flattened
+func archiveFiles(archivePath string, archive *zip.ReadCloser) []matchFile {
+	entries := make([]*zip.File, 0, len(archive.File))
+	for _, entry := range archive.File {
+		if !entry.FileInfo().IsDir() {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+
+	files := make([]matchFile, 0, len(entries))
+	for _, entry := range entries {
+		files = append(files, matchFile{
+			path: archivePath + "/" + entry.Name,
+			read: func() ([]byte, error) {
+				reader, err := entry.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer func(reader io.ReadCloser) {
+					_ = reader.Close()
+				}(reader)
+				return io.ReadAll(reader)
+			},
+		})
+	}
+	return files
+}
+
+// inputFiles files of a directory, a zip archive or the files matching a glob pattern
+func inputFiles(input string) ([]string, error) {
+	info, err := os.Stat(input)
+	if err == nil && info.IsDir() {
+		entries, err := os.ReadDir(input)
+		if err != nil {
+			return nil, err
+		}
+		paths := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				paths = append(paths, filepath.Join(input, entry.Name()))
+			}
+		}
+		return paths, nil
+	}
+
+	paths, err := filepath.Glob(input)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			files = append(files, path)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// checkMatchInfo teams and date of the match are required to save its event
+func checkMatchInfo(info jsonparser.Info) error {
+	if len(info.Teams) != 2 || len(info.Dates) == 0 {
+		return ErrIncompleteInfo
+	}
+	return nil
+}
+
+// allowsMatch whether the match passes the match type and gender filters
+func (config IngestConfig) allowsMatch(info jsonparser.Info) bool {
+	return allowsValue(info.MatchType, config.IncludeMatchTypes, config.ExcludeMatchTypes) &&
+		allowsValue(info.Gender, config.IncludeGenders, config.ExcludeGenders)
+}
+
+func allowsValue(value string, include []string, exclude []string) bool {
+	if len(include) > 0 && !slices.Contains(include, value) {
+		return false
+	}
+	return !slices.Contains(exclude, value)
+}
@@ -0,0 +1,265 @@
+package internal
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	jsonparser "cricket/pkg/json_parser"
+)
+
+func TestAllowsValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		include []string
+		exclude []string
+		want    bool
+	}{
+		{name: "no filters", value: "T20", want: true},
+		{name: "included", value: "ODI", include: []string{"ODI", "Test"}, want: true},
+		{name: "not included", value: "T20", include: []string{"ODI", "Test"}, want: false},
+		{name: "excluded", value: "female", exclude: []string{"female"}, want: false},
+		{name: "not excluded", value: "male", exclude: []string{"female"}, want: true},
+		{name: "included and excluded", value: "Test", include: []string{"Test"}, exclude: []string{"Test"}, want: false},
+		{name: "match is case sensitive", value: "odi", include: []string{"ODI"}, want: false},
+		{name: "empty value with include", value: "", include: []string{"ODI"}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := allowsValue(test.value, test.include, test.exclude)
+			if got != test.want {
+				t.Errorf("allowsValue(%q, %v, %v) = %v, want %v", test.value, test.include, test.exclude, got, test.want)
+			}
+		})
+	}
+}
+
+func TestAllowsMatch(t *testing.T) {
+	config := IngestConfig{IncludeMatchTypes: []string{"ODI"}, ExcludeGenders: []string{"female"}}
+	tests := []struct {
+		name string
+		info jsonparser.Info
+		want bool
+	}{
+		{name: "both filters pass", info: jsonparser.Info{MatchType: "ODI", Gender: "male"}, want: true},
+		{name: "match type filtered", info: jsonparser.Info{MatchType: "T20", Gender: "male"}, want: false},
+		{name: "gender filtered", info: jsonparser.Info{MatchType: "ODI", Gender: "female"}, want: false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := config.allowsMatch(test.info); got != test.want {
+				t.Errorf("allowsMatch(%+v) = %v, want %v", test.info, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchFileId(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    int
+		wantErr bool
+	}{
+		{path: "t20s_male_json/1234.json", want: 1234},
+		{path: "odis_json.zip/odis/64012.yaml", want: 64012},
+		{path: "ipl_csv2/335982.csv", want: 335982},
+		{path: "t20s_male_json/README.txt", wantErr: true},
+		{path: "t20s_male_json/0.json", wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			got, err := matchFileId(test.path)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("matchFileId(%q) = %d, want error", test.path, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("matchFileId(%q) = %d, %v, want %d", test.path, got, err, test.want)
+			}
+		})
+	}
+}
+
+// writeZip creates a zip archive with the files, directories are entries ending with /
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	writer := zip.NewWriter(file)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func matchFilePaths(files []matchFile) []string {
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.path)
+	}
+	return paths
+}
+
+func TestArchiveFiles(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "odis_json.zip")
+	writeZip(t, archivePath, map[string]string{
+		"2.json":      "second",
+		"1.json":      "first",
+		"odis/":       "",
+		"odis/3.json": "third",
+		"README.txt":  "readme",
+	})
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(archive *zip.ReadCloser) {
+		_ = archive.Close()
+	}(archive)
+
+	files := archiveFiles(archivePath, archive)
+	want := []string{
+		archivePath + "/1.json",
+		archivePath + "/2.json",
+		archivePath + "/README.txt",
+		archivePath + "/odis/3.json",
+	}
+	if got := matchFilePaths(files); !slices.Equal(got, want) {
+		t.Fatalf("archiveFiles paths = %v, want %v", got, want)
+	}
+
+	contents := []string{"first", "second", "readme", "third"}
+	for index, file := range files {
+		content, err := file.read()
+		if err != nil {
+			t.Fatalf("reading %s: %v", file.path, err)
+		}
+		if string(content) != contents[index] {
+			t.Errorf("content of %s = %q, want %q", file.path, content, contents[index])
+		}
+	}
+}
+
+func TestMatchFiles(t *testing.T) {
+	dir := t.TempDir()
+	jsonDir := filepath.Join(dir, "t20s_json")
+	if err := os.Mkdir(jsonDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(jsonDir, "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, jsonDir, map[string]string{"20.json": "{}", "10.json": "{}", "nested/30.json": "{}"})
+	archivePath := filepath.Join(dir, "odis_json.zip")
+	writeZip(t, archivePath, map[string]string{"5.json": "{}", "4.json": "{}"})
+
+	tests := []struct {
+		name    string
+		inputs  []string
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "directory without nested directories",
+			inputs: []string{jsonDir},
+			want:   []string{filepath.Join(jsonDir, "10.json"), filepath.Join(jsonDir, "20.json")},
+		},
+		{
+			name:   "zip archive",
+			inputs: []string{archivePath},
+			want:   []string{archivePath + "/4.json", archivePath + "/5.json"},
+		},
+		{
+			name:   "glob pattern",
+			inputs: []string{filepath.Join(jsonDir, "2*.json")},
+			want:   []string{filepath.Join(jsonDir, "20.json")},
+		},
+		{
+			name:   "inputs in order and files once",
+			inputs: []string{archivePath, jsonDir, filepath.Join(jsonDir, "*.json")},
+			want: []string{
+				archivePath + "/4.json",
+				archivePath + "/5.json",
+				filepath.Join(jsonDir, "10.json"),
+				filepath.Join(jsonDir, "20.json"),
+			},
+		},
+		{
+			name:    "no files",
+			inputs:  []string{filepath.Join(dir, "missing", "*.json")},
+			wantErr: ErrNoMatchFiles,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			files, closeArchives, err := IngestConfig{Inputs: test.inputs}.matchFiles()
+			if test.wantErr != nil {
+				if !errors.Is(err, test.wantErr) {
+					t.Fatalf("matchFiles error = %v, want %v", err, test.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closeArchives()
+			if got := matchFilePaths(files); !slices.Equal(got, test.want) {
+				t.Errorf("matchFiles paths = %v, want %v", got, test.want)
+			}
+			for _, file := range files {
+				if _, err := file.read(); err != nil {
+					t.Errorf("reading %s: %v", file.path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckMatchInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		info jsonparser.Info
+		want error
+	}{
+		{name: "complete", info: jsonparser.Info{Teams: []string{"India", "Australia"}, Dates: []string{"2023-11-19"}}},
+		{name: "without date", info: jsonparser.Info{Teams: []string{"India", "Australia"}}, want: ErrIncompleteInfo},
+		{name: "one team", info: jsonparser.Info{Teams: []string{"India"}, Dates: []string{"2023-11-19"}}, want: ErrIncompleteInfo},
+		{name: "empty info", want: ErrIncompleteInfo},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkMatchInfo(test.info); !errors.Is(err, test.want) {
+				t.Errorf("checkMatchInfo error = %v, want %v", err, test.want)
+			}
+		})
+	}
+}
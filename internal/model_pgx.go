@@ -56,30 +56,66 @@ type Wicket struct {
 	Event  Event
 }
 
+// Innings Ordinal starts from 1 in the order innings are played. super overs are stored as separate innings.
+type Innings struct {
+	ID            int64
+	Event         Event
+	Ordinal       int
+	BattingTeam   Team
+	TargetRuns    int
+	TargetOvers   float64
+	TargetRevised bool
+	Declared      bool
+	Forfeited     bool
+	SuperOver     bool
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
 type BallInfo struct {
 	ID          int64
 	Event       Event
+	Innings     Innings
 	Over        int
 	Ball        int
+	Delivery    int
+	LegalBall   int
+	IsLegal     bool
+	OverLabel   string
 	BattingTeam Team
 	Batsman     Player
 	Bowler      Player
 	NonStriker  Player
 	StrikerRun  int
 	ExtraRun    int
-	Wickets     Wicket
+	Wides       int
+	NoBalls     int
+	Byes        int
+	LegByes     int
+	Penalty     int
+	Wickets     []Wicket
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
 // EndResult match result cannot be calculated every time by calculating ball info.
 // once match is completed, calculate basic details and store it in below struct
+// Result is one of won, tie, draw or no result. Summary is the readable result of the match.
 type EndResult struct {
 	Event            Event
 	Result           string
+	Summary          string
 	TeamWon          Team
+	WinByRuns        int
+	WinByWickets     int
+	WinByInnings     int
+	Method           string
+	Eliminator       Team
+	BowlOut          Team
 	TeamAScore       int
+	TeamAWickets     int
 	TeamBScore       int
+	TeamBWickets     int
 	PlayerOfTheMatch Player
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
@@ -0,0 +1,227 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	phasePowerplay = "powerplay"
+	phaseMiddle    = "middle"
+	phaseDeath     = "death"
+)
+
+// phaseExpression phase of the ball selected with alias bi from the event with alias e and its
+// innings with alias il.
+// powerplay is taken from the mandatory powerplay of the innings, and when the innings does not have
+// powerplays stored it is the first 6 overs of T20s and first 10 overs of longer formats.
+// death overs are the last 5 overs of T20s and the last 10 overs of longer formats, counted from the
+// scheduled length of the innings. it is the target overs when the chase is reduced, a partial over
+// like 17.3 is taken as 18 overs. innings ending early, eg: all out, do not move the death overs.
+const phaseExpression = `
+			CASE
+			    WHEN EXISTS(
+			        SELECT 1 FROM powerplay AS pp
+			        WHERE pp.innings = bi.innings AND pp.type = 'mandatory'
+			            AND bi.over + bi.legal_ball / 10.0 BETWEEN pp.from_over AND pp.to_over
+			    ) THEN 'powerplay'
+			    WHEN NOT EXISTS(SELECT 1 FROM powerplay AS pp WHERE pp.innings = bi.innings)
+			        AND bi.over < CASE WHEN e.overs <= 20 THEN 6 ELSE 10 END THEN 'powerplay'
+			    WHEN bi.over >= COALESCE(CEIL(il.target_overs), e.overs) - CASE WHEN e.overs <= 20 THEN 5 ELSE 10 END
+			        THEN 'death'
+			    ELSE 'middle'
+			END`
+
+type PhaseStats struct {
+	Phase              string  `json:"phase"`
+	Runs               int     `json:"runs"`
+	Balls              int     `json:"balls"`
+	RunRate            float64 `json:"run_rate"`
+	Wickets            int     `json:"wickets"`
+	Boundaries         int     `json:"boundaries"`
+	BoundaryPercentage float64 `json:"boundary_percentage"`
+	Dots               int     `json:"dots"`
+	DotPercentage      float64 `json:"dot_percentage"`
+}
+
+// PhaseGroup phase stats of a team, player or tournament. name is batting or bowling
+// for teams and players, and the tournament name with season for tournaments.
+type PhaseGroup struct {
+	Name   string       `json:"name"`
+	Phases []PhaseStats `json:"phases"`
+}
+
+type phaseRow struct {
+	GroupKey   string `db:"group_key"`
+	Phase      string `db:"phase"`
+	Runs       int    `db:"runs"`
+	Balls      int    `db:"balls"`
+	Wickets    int    `db:"wickets"`
+	Boundaries int    `db:"boundaries"`
+	Dots       int    `db:"dots"`
+}
+
+// phaseQuery columns of the phase analysis which differ between batting and bowling perspective.
+// every field is a sql expression on ball_info with alias bi.
+type phaseQuery struct {
+	groupKey  string
+	condition string
+	runs      string
+	balls     string
+	wickets   string
+}
+
+var (
+	// teamPhaseQuery runs of the team including extras
+	teamPhaseQuery = phaseQuery{
+		runs:    "bi.striker_run + bi.extra_run",
+		balls:   legalBallCondition,
+		wickets: "COALESCE(bw.wickets, 0)",
+	}
+	batterPhaseQuery = phaseQuery{
+		groupKey:  "'batting'",
+		condition: "bi.batsman = @player",
+		runs:      "bi.striker_run",
+		balls:     ballFacedCondition,
+		wickets: "(SELECT COUNT(*) FROM wicket AS pw " +
+			"WHERE pw.ball_info = bi.id AND pw.player = @player AND pw.kind NOT IN " + notOutWicketKinds + ")",
+	}
+	bowlerPhaseQuery = phaseQuery{
+		groupKey:  "'bowling'",
+		condition: "bi.bowler = @player",
+		runs:      "bi.striker_run + bi.wides + bi.noballs",
+		balls:     legalBallCondition,
+		wickets:   "COALESCE(bw.bowler_wickets, 0)",
+	}
+)
+
+// queryPhases phase wise stats of limited overs matches. test matches are left out as they do not have phases.
+func queryPhases(query phaseQuery, filter StatsFilter, namedArgs pgx.NamedArgs, appInstance *app.App) ([]PhaseGroup, error) {
+	sqlQuery, namedArgs := phasesQuery(query, filter, namedArgs)
+	rows, err := appInstance.DB.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	phaseRows, err := pgx.CollectRows(rows, pgx.RowToStructByName[phaseRow])
+	if err != nil {
+		return nil, err
+	}
+	return groupPhases(phaseRows), nil
+}
+
+// phasesQuery named args of the query are merged with the args of the filter, so they should not
+// use the names of the filter args like @team
+func phasesQuery(query phaseQuery, filter StatsFilter, namedArgs pgx.NamedArgs) (string, pgx.NamedArgs) {
+	condition, filterArgs := filter.eventCondition("e")
+	for key, value := range filterArgs {
+		namedArgs[key] = value
+	}
+	sqlQuery := fmt.Sprintf(`
+			WITH phased AS (
+			    SELECT
+			        %[1]s AS group_key,
+			        %[2]s AS phase,
+			        %[3]s AS runs,
+			        %[4]s AS is_ball,
+			        %[5]s AS wickets,
+			        bi.striker_run
+			    FROM ball_info AS bi
+			        JOIN event AS e ON e.id = bi.event AND e.overs > 0 AND %[6]s
+			        JOIN innings AS il ON il.id = bi.innings
+			        LEFT JOIN (%[7]s) AS bw ON bw.ball_info = bi.id
+			    WHERE %[8]s AND %[9]s
+			)
+			SELECT
+			    group_key,
+			    phase,
+			    COALESCE(SUM(runs), 0) AS runs,
+			    COUNT(CASE WHEN is_ball THEN 1 END) AS balls,
+			    COALESCE(SUM(wickets), 0) AS wickets,
+			    COUNT(CASE WHEN striker_run IN (4, 6) THEN 1 END) AS boundaries,
+			    COUNT(CASE WHEN is_ball AND runs = 0 THEN 1 END) AS dots
+			FROM phased
+			GROUP BY group_key, phase`,
+		query.groupKey, phaseExpression, query.runs, query.balls, query.wickets,
+		condition, ballWicketsQuery, query.condition, filter.ballCondition("bi"),
+	)
+	return sqlQuery, namedArgs
+}
+
+// groupPhases groups the rows by group key, phases are in the order they are played
+func groupPhases(rows []phaseRow) []PhaseGroup {
+	phaseOrder := map[string]int{phasePowerplay: 0, phaseMiddle: 1, phaseDeath: 2}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].GroupKey != rows[j].GroupKey {
+			return rows[i].GroupKey < rows[j].GroupKey
+		}
+		return phaseOrder[rows[i].Phase] < phaseOrder[rows[j].Phase]
+	})
+
+	groups := make([]PhaseGroup, 0)
+	for _, row := range rows {
+		if len(groups) == 0 || groups[len(groups)-1].Name != row.GroupKey {
+			groups = append(groups, PhaseGroup{Name: row.GroupKey})
+		}
+		group := &groups[len(groups)-1]
+		group.Phases = append(group.Phases, PhaseStats{
+			Phase:              row.Phase,
+			Runs:               row.Runs,
+			Balls:              row.Balls,
+			RunRate:            economy(row.Runs, row.Balls),
+			Wickets:            row.Wickets,
+			Boundaries:         row.Boundaries,
+			BoundaryPercentage: percentage(row.Boundaries, row.Balls),
+			Dots:               row.Dots,
+			DotPercentage:      percentage(row.Dots, row.Balls),
+		})
+	}
+	return groups
+}
+
+// QueryTournamentPhases phase stats of every tournament matching the filter
+func QueryTournamentPhases(filter StatsFilter, appInstance *app.App) ([]PhaseGroup, error) {
+	query := teamPhaseQuery
+	query.groupKey = "TRIM(e.name || ' ' || COALESCE(e.season, ''))"
+	query.condition = "TRUE"
+	return queryPhases(query, filter, pgx.NamedArgs{}, appInstance)
+}
+
+// QueryTeamPhases phase stats of the team while batting and bowling
+func QueryTeamPhases(teamId int, filter StatsFilter, appInstance *app.App) ([]PhaseGroup, error) {
+	_, err := getTeamName(teamId, appInstance.DB)
+	if err != nil {
+		return nil, err
+	}
+	query, namedArgs := teamPhases(teamId)
+	return queryPhases(query, filter, namedArgs, appInstance)
+}
+
+// teamPhases team id is passed as @team_id, @team is the team name of the filter
+func teamPhases(teamId int) (phaseQuery, pgx.NamedArgs) {
+	query := teamPhaseQuery
+	query.groupKey = "CASE WHEN bi.batting_team = @team_id THEN 'batting' ELSE 'bowling' END"
+	query.condition = "@team_id IN (e.team_a, e.team_b)"
+	return query, pgx.NamedArgs{"team_id": teamId}
+}
+
+// QueryPlayerPhases phase stats of the player as a batter and as a bowler
+func QueryPlayerPhases(playerId int, filter StatsFilter, appInstance *app.App) ([]PhaseGroup, error) {
+	_, err := getPlayerName(playerId, appInstance.DB)
+	if err != nil {
+		return nil, err
+	}
+	batting, err := queryPhases(batterPhaseQuery, filter, pgx.NamedArgs{"player": playerId}, appInstance)
+	if err != nil {
+		return nil, err
+	}
+	bowling, err := queryPhases(bowlerPhaseQuery, filter, pgx.NamedArgs{"player": playerId}, appInstance)
+	if err != nil {
+		return nil, err
+	}
+	return append(batting, bowling...), nil
+}
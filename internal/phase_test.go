@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestTeamPhasesWithTeamFilter(t *testing.T) {
+	teamId := 7
+	query, namedArgs := teamPhases(teamId)
+	sqlQuery, namedArgs := phasesQuery(query, StatsFilter{Team: "India"}, namedArgs)
+
+	rewritten, args, err := pgx.StrictNamedArgs(namedArgs).RewriteQuery(context.Background(), nil, sqlQuery, nil)
+	if err != nil {
+		t.Fatalf("rewriting query: %v", err)
+	}
+	if !slices.Contains(args, any(teamId)) {
+		t.Errorf("args = %v, want the team id %d", args, teamId)
+	}
+	match := teamFilterArg.FindStringSubmatch(rewritten)
+	if match == nil {
+		t.Fatalf("query does not have the team filter: %s", rewritten)
+	}
+	position, _ := strconv.Atoi(match[1])
+	if args[position-1] != "India" {
+		t.Errorf("team filter arg = %v, want India", args[position-1])
+	}
+}
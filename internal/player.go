@@ -0,0 +1,239 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrPlayerNotFound = errors.New("player not found")
+
+type PlayerBattingResponse struct {
+	PlayerId     int     `json:"player_id"`
+	Name         string  `json:"name"`
+	Matches      int     `json:"matches"`
+	Innings      int     `json:"innings"`
+	NotOuts      int     `json:"not_outs"`
+	Runs         int     `json:"runs"`
+	Balls        int     `json:"balls"`
+	Average      float64 `json:"average"`
+	StrikeRate   float64 `json:"strike_rate"`
+	HighestScore string  `json:"highest_score"`
+	Fifties      int     `json:"fifties"`
+	Hundreds     int     `json:"hundreds"`
+	Ducks        int     `json:"ducks"`
+	Fours        int     `json:"fours"`
+	Sixes        int     `json:"sixes"`
+}
+
+// BowlerWicketResponse dismissals credited to the bowler, run outs and retirements are not part of it
+type BowlerWicketResponse struct {
+	Caught          int `db:"caught" json:"caught"`
+	Bowled          int `db:"bowled" json:"bowled"`
+	Stumped         int `db:"stumped" json:"stumped"`
+	LBW             int `db:"lbw" json:"lbw"`
+	CaughtAndBowled int `db:"caught_and_bowled" json:"caught_and_bowled"`
+	HitWicket       int `db:"hit_wicket" json:"hit_wicket"`
+}
+
+type PlayerBowlingResponse struct {
+	PlayerId      int                  `json:"player_id"`
+	Name          string               `json:"name"`
+	Innings       int                  `json:"innings"`
+	Balls         int                  `json:"balls"`
+	Runs          int                  `json:"runs"`
+	Wickets       int                  `json:"wickets"`
+	Average       float64              `json:"average"`
+	Economy       float64              `json:"economy"`
+	StrikeRate    float64              `json:"strike_rate"`
+	BestFigures   string               `json:"best_figures"`
+	ThreeWickets  int                  `json:"three_wickets"`
+	FiveWickets   int                  `json:"five_wickets"`
+	DismissalKind BowlerWicketResponse `json:"dismissal_kind"`
+}
+
+func getPlayerName(playerId int, dbPool *pgxpool.Pool) (string, error) {
+	sqlQuery := `SELECT name FROM player WHERE id = @player`
+	namedArgs := pgx.NamedArgs{"player": playerId}
+
+	var name string
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrPlayerNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// playerMatches matches in which the player is part of playing 11
+func playerMatches(playerId int, filter StatsFilter, dbPool *pgxpool.Pool) (int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			SELECT COUNT(*) FROM event AS e
+			WHERE (@player = ANY(e.playing_11_a_ids) OR @player = ANY(e.playing_11_b_ids)) AND %s`, condition)
+	namedArgs["player"] = playerId
+
+	var matches int
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&matches)
+	if err != nil {
+		return 0, err
+	}
+	return matches, nil
+}
+
+// QueryPlayerBatting career batting stats of the player. an innings is counted when the player
+// has been at the crease either as striker or non-striker.
+func QueryPlayerBatting(playerId int, filter StatsFilter, appInstance *app.App) (PlayerBattingResponse, error) {
+	name, err := getPlayerName(playerId, appInstance.DB)
+	if err != nil {
+		return PlayerBattingResponse{}, err
+	}
+	matches, err := playerMatches(playerId, filter, appInstance.DB)
+	if err != nil {
+		return PlayerBattingResponse{}, err
+	}
+
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			WITH batting_innings AS (
+			    SELECT
+			        bi.innings,
+			        COALESCE(SUM(CASE WHEN bi.batsman = @player THEN bi.striker_run END), 0) AS runs,
+			        COUNT(CASE WHEN bi.batsman = @player AND %[2]s THEN 1 END) AS balls,
+			        COUNT(CASE WHEN bi.batsman = @player AND bi.striker_run = 4 THEN 1 END) AS fours,
+			        COUNT(CASE WHEN bi.batsman = @player AND bi.striker_run = 6 THEN 1 END) AS sixes
+			    FROM ball_info AS bi JOIN event AS e ON e.id = bi.event AND %[1]s
+			    WHERE (bi.batsman = @player OR bi.non_striker = @player) AND %[3]s
+			    GROUP BY bi.innings
+			), dismissals AS (
+			    SELECT DISTINCT bi.innings
+			    FROM ball_info AS bi JOIN wicket AS w ON w.ball_info = bi.id
+			    WHERE w.player = @player AND w.kind NOT IN %[4]s
+			), scores AS (
+			    SELECT i.*, d.innings IS NULL AS not_out
+			    FROM batting_innings AS i
+			        LEFT JOIN dismissals AS d ON d.innings = i.innings
+			)
+			SELECT
+			    COUNT(*),
+			    COUNT(CASE WHEN not_out THEN 1 END),
+			    COALESCE(SUM(runs), 0),
+			    COALESCE(SUM(balls), 0),
+			    COALESCE((
+			        SELECT runs || CASE WHEN not_out THEN '*' ELSE '' END
+			        FROM scores ORDER BY runs DESC, not_out DESC LIMIT 1
+			    ), ''),
+			    COUNT(CASE WHEN runs >= 50 AND runs < 100 THEN 1 END),
+			    COUNT(CASE WHEN runs >= 100 THEN 1 END),
+			    COUNT(CASE WHEN runs = 0 AND NOT not_out THEN 1 END),
+			    COALESCE(SUM(fours), 0),
+			    COALESCE(SUM(sixes), 0)
+			FROM scores`, condition, ballFacedCondition, filter.ballCondition("bi"), notOutWicketKinds)
+	namedArgs["player"] = playerId
+
+	stats := PlayerBattingResponse{PlayerId: playerId, Name: name, Matches: matches}
+	err = appInstance.DB.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(
+		&stats.Innings,
+		&stats.NotOuts,
+		&stats.Runs,
+		&stats.Balls,
+		&stats.HighestScore,
+		&stats.Fifties,
+		&stats.Hundreds,
+		&stats.Ducks,
+		&stats.Fours,
+		&stats.Sixes,
+	)
+	if err != nil {
+		return PlayerBattingResponse{}, err
+	}
+	stats.Average = average(stats.Runs, stats.Innings-stats.NotOuts)
+	stats.StrikeRate = strikeRate(stats.Runs, stats.Balls)
+	return stats, nil
+}
+
+// QueryPlayerBowling career bowling stats of the player. runs conceded does not include byes and leg byes.
+func QueryPlayerBowling(playerId int, filter StatsFilter, appInstance *app.App) (PlayerBowlingResponse, error) {
+	name, err := getPlayerName(playerId, appInstance.DB)
+	if err != nil {
+		return PlayerBowlingResponse{}, err
+	}
+
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			WITH bowling_innings AS (
+			    SELECT
+			        bi.innings,
+			        COUNT(CASE WHEN %[2]s THEN 1 END) AS balls,
+			        SUM(bi.striker_run + bi.wides + bi.noballs) AS runs,
+			        COALESCE(SUM(bw.bowler_wickets), 0) AS wickets
+			    FROM ball_info AS bi
+			        JOIN event AS e ON e.id = bi.event AND %[1]s
+			        LEFT JOIN (%[3]s) AS bw ON bw.ball_info = bi.id
+			    WHERE bi.bowler = @player AND %[4]s
+			    GROUP BY bi.innings
+			)
+			SELECT
+			    COUNT(*),
+			    COALESCE(SUM(balls), 0),
+			    COALESCE(SUM(runs), 0),
+			    COALESCE(SUM(wickets), 0),
+			    COALESCE((SELECT wickets || '/' || runs FROM bowling_innings ORDER BY wickets DESC, runs LIMIT 1), ''),
+			    COUNT(CASE WHEN wickets >= 3 AND wickets < 5 THEN 1 END),
+			    COUNT(CASE WHEN wickets >= 5 THEN 1 END)
+			FROM bowling_innings`, condition, legalBallCondition, ballWicketsQuery, filter.ballCondition("bi"))
+	namedArgs["player"] = playerId
+
+	stats := PlayerBowlingResponse{PlayerId: playerId, Name: name}
+	err = appInstance.DB.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(
+		&stats.Innings,
+		&stats.Balls,
+		&stats.Runs,
+		&stats.Wickets,
+		&stats.BestFigures,
+		&stats.ThreeWickets,
+		&stats.FiveWickets,
+	)
+	if err != nil {
+		return PlayerBowlingResponse{}, err
+	}
+
+	stats.DismissalKind, err = playerWicketKinds(playerId, filter, appInstance.DB)
+	if err != nil {
+		return PlayerBowlingResponse{}, err
+	}
+	stats.Average = average(stats.Runs, stats.Wickets)
+	stats.Economy = economy(stats.Runs, stats.Balls)
+	if stats.Wickets > 0 {
+		stats.StrikeRate = roundTwoDecimals(float64(stats.Balls) / float64(stats.Wickets))
+	}
+	return stats, nil
+}
+
+// playerWicketKinds dismissals credited to the bowler grouped by kind
+func playerWicketKinds(playerId int, filter StatsFilter, dbPool *pgxpool.Pool) (BowlerWicketResponse, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`SELECT 
+    				COUNT(CASE WHEN w.kind = 'caught' THEN 1 END) as caught,
+    				COUNT(CASE WHEN w.kind = 'bowled' THEN 1 END) as bowled,
+    				COUNT(CASE WHEN w.kind = 'stumped' THEN 1 END) as stumped,
+    				COUNT(CASE WHEN w.kind = 'lbw' THEN 1 END) as lbw,
+    				COUNT(CASE WHEN w.kind = 'caught and bowled' THEN 1 END) as caught_and_bowled,
+    				COUNT(CASE WHEN w.kind = 'hit wicket' THEN 1 END) as hit_wicket
+				FROM wicket as w JOIN event as e ON w.event = e.id AND %s
+				WHERE w.bowler = @player AND w.kind IN %s AND %s`, condition, bowlerWicketKinds, filter.wicketCondition("w"))
+	namedArgs["player"] = playerId
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return BowlerWicketResponse{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[BowlerWicketResponse])
+}
@@ -26,6 +26,14 @@ type WicketResponse struct {
 	CaughtAndBowled int `db:"caught_and_bowled" json:"caught_and_bowled"`
 }
 
+type ExtrasResponse struct {
+	Wides   int `db:"wides" json:"wides"`
+	NoBalls int `db:"noballs" json:"noballs"`
+	Byes    int `db:"byes" json:"byes"`
+	LegByes int `db:"legbyes" json:"legbyes"`
+	Penalty int `db:"penalty" json:"penalty"`
+}
+
 type TournamentStatsResponse struct {
 	Matches      int            `json:"matches"`
 	TeamsCount   int            `json:"teams_count"`
@@ -34,9 +42,43 @@ type TournamentStatsResponse struct {
 	Sixes        int            `json:"sixes"`
 	Wickets      WicketResponse `json:"wickets"`
 	BallsBowled  int            `json:"balls_bowled"`
+	Extras       ExtrasResponse `json:"extras"`
+	Errors       []string       `json:"errors,omitempty"`
 	//drawMatches  int
 }
 
+// allMatchTypes is the key of the aggregated bucket in the tournament stats response
+const allMatchTypes = "all"
+
+const (
+	// legalBallCondition wides and no-balls are not counted in the over
+	legalBallCondition = "bi.is_legal"
+	// ballFacedCondition no-balls are counted as faced by the batter, wides are not
+	ballFacedCondition = "bi.wides = 0"
+	// bowlerWicketKinds dismissals credited to the bowler
+	bowlerWicketKinds = "('bowled', 'caught', 'caught and bowled', 'lbw', 'stumped', 'hit wicket')"
+	// notOutWicketKinds retirements after which the batter is not out
+	notOutWicketKinds = "('retired hurt', 'retired not out')"
+)
+
+// extrasColumns extras of the balls selected with alias bi, in the order of ExtrasResponse fields
+const extrasColumns = `
+			    COALESCE(SUM(bi.wides), 0) AS wides,
+			    COALESCE(SUM(bi.noballs), 0) AS noballs,
+			    COALESCE(SUM(bi.byes), 0) AS byes,
+			    COALESCE(SUM(bi.legbyes), 0) AS legbyes,
+			    COALESCE(SUM(bi.penalty), 0) AS penalty`
+
+// ballWicketsQuery wickets of each ball. a delivery can have more than one wicket, so wicket table is
+// aggregated before joining with ball_info to avoid counting the runs of the ball twice.
+var ballWicketsQuery = `
+			SELECT
+			    w.ball_info,
+			    COUNT(CASE WHEN w.kind <> 'retired hurt' THEN 1 END) AS wickets,
+			    COUNT(CASE WHEN w.kind IN ` + bowlerWicketKinds + ` THEN 1 END) AS bowler_wickets
+			FROM wicket AS w
+			GROUP BY w.ball_info`
+
 func (service pgDB) QueryDB(sqlQuery string) (pgx.Rows, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -58,10 +100,11 @@ func (service pgDB) QueryCount(sqlQuery string) (int, error) {
 	return count, nil
 }
 
-func queryUniqueEvents(dBPool *pgxpool.Pool) ([]string, error) {
-	eventQuery := `SELECT DISTINCT(match_type) FROM event`
+func queryUniqueEvents(filter StatsFilter, dBPool *pgxpool.Pool) ([]string, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	eventQuery := fmt.Sprintf(`SELECT DISTINCT(e.match_type) FROM event AS e WHERE %s`, condition)
 
-	rows, err := dBPool.Query(context.TODO(), eventQuery)
+	rows, err := dBPool.Query(context.TODO(), eventQuery, namedArgs)
 	if err != nil {
 		return nil, err
 	}
@@ -79,9 +122,9 @@ func queryUniqueEvents(dBPool *pgxpool.Pool) ([]string, error) {
 	return matchTypes, nil
 }
 
-func totalMatches(matchType string, dbPool *pgxpool.Pool) (int, error) {
-	sqlQuery := `SELECT COUNT(*) FROM event where match_type = @match_type`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+func totalMatches(filter StatsFilter, dbPool *pgxpool.Pool) (int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`SELECT COUNT(*) FROM event AS e WHERE %s`, condition)
 	var matchCount int
 	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&matchCount)
 	if err != nil {
@@ -90,15 +133,15 @@ func totalMatches(matchType string, dbPool *pgxpool.Pool) (int, error) {
 	return matchCount, nil
 }
 
-func tournamentTeamsCount(matchType string, dbPool *pgxpool.Pool) (int, error) {
-	sqlQuery := `
+func tournamentTeamsCount(filter StatsFilter, dbPool *pgxpool.Pool) (int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
 			SELECT COUNT(DISTINCT team) as unique_teams FROM (
-			    SELECT event.team_a AS team from event where event.match_type = @match_type
+			    SELECT e.team_a AS team from event AS e where %[1]s
 			UNION 
-				SELECT event.team_b from event where event.match_type = @match_type
+				SELECT e.team_b from event AS e where %[1]s
 			)as merged_teams;
-			`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+			`, condition)
 	var teamCount int
 	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&teamCount)
 	if err != nil {
@@ -108,16 +151,16 @@ func tournamentTeamsCount(matchType string, dbPool *pgxpool.Pool) (int, error) {
 }
 
 // returns teams, players distinct count
-func tournamentPlayersCount(matchType string, dbPool *pgxpool.Pool) (int, error) {
-	sqlQuery := `
+func tournamentPlayersCount(filter StatsFilter, dbPool *pgxpool.Pool) (int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
 			SELECT COUNT(DISTINCT players) as unique_players
 			FROM LATERAL (
-		            SELECT UNNEST(event.playing_11_a_ids) AS players from event where event.match_type = @match_type
+		            SELECT UNNEST(e.playing_11_a_ids) AS players from event AS e where %[1]s
 		        UNION 
-		            SELECT UNNEST(event.playing_11_b_ids) AS players from event where event.match_type = @match_type)
+		            SELECT UNNEST(e.playing_11_b_ids) AS players from event AS e where %[1]s)
 		    as merged_players;
-			`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+			`, condition)
 	var playersCount int
 	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&playersCount)
 	if err != nil {
@@ -126,13 +169,14 @@ func tournamentPlayersCount(matchType string, dbPool *pgxpool.Pool) (int, error)
 	return playersCount, nil
 }
 
-func tournamentBoundariesCount(matchType string, dbPool *pgxpool.Pool) (int, int, error) {
-	sqlQuery := `
+func tournamentBoundariesCount(filter StatsFilter, dbPool *pgxpool.Pool) (int, int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
 				SELECT 
 				    COUNT(CASE WHEN bi.striker_run = 4 THEN 1 END) AS boundaries,
 				    COUNT(CASE WHEN bi.striker_run = 6 THEN 1 END) AS sixes
-				FROM ball_info as bi JOIN event as e on bi.event = e.id and e.match_type = @match_type`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+				FROM ball_info as bi JOIN event as e on bi.event = e.id and %s
+				WHERE %s`, condition, filter.ballCondition("bi"))
 
 	var boundariesCount, sixesCount int
 	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&boundariesCount, &sixesCount)
@@ -142,9 +186,11 @@ func tournamentBoundariesCount(matchType string, dbPool *pgxpool.Pool) (int, int
 	return boundariesCount, sixesCount, nil
 }
 
-func tournamentBallsBowled(matchType string, dbPool *pgxpool.Pool) (int, error) {
-	sqlQuery := `SELECT COUNT(*) FROM ball_info as bi JOIN event as e ON bi.event = e.id AND e.match_type = @match_type`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+func tournamentBallsBowled(filter StatsFilter, dbPool *pgxpool.Pool) (int, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(
+		`SELECT COUNT(*) FROM ball_info as bi JOIN event as e ON bi.event = e.id AND %s WHERE %s AND %s`,
+		condition, legalBallCondition, filter.ballCondition("bi"))
 
 	var ballsBowled int
 	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&ballsBowled)
@@ -154,8 +200,22 @@ func tournamentBallsBowled(matchType string, dbPool *pgxpool.Pool) (int, error)
 	return ballsBowled, nil
 }
 
-func tournamentWickets(matchType string, dbPool *pgxpool.Pool) (WicketResponse, error) {
-	sqlQuery := `SELECT 
+func tournamentExtras(filter StatsFilter, dbPool *pgxpool.Pool) (ExtrasResponse, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(
+		`SELECT %s FROM ball_info as bi JOIN event as e ON bi.event = e.id AND %s WHERE %s`,
+		extrasColumns, condition, filter.ballCondition("bi"))
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return ExtrasResponse{}, err
+	}
+	return pgx.CollectOneRow(rows, pgx.RowToStructByName[ExtrasResponse])
+}
+
+func tournamentWickets(filter StatsFilter, dbPool *pgxpool.Pool) (WicketResponse, error) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`SELECT 
     				COUNT(CASE WHEN w.kind = 'caught' THEN 1 END) as caught,
     				COUNT(CASE WHEN w.kind = 'bowled' THEN 1 END) as bowled,
     				COUNT(CASE WHEN w.kind = 'stumped' THEN 1 END) as stumped,
@@ -163,8 +223,8 @@ func tournamentWickets(matchType string, dbPool *pgxpool.Pool) (WicketResponse,
     				COUNT(CASE WHEN w.kind = 'lbw' THEN 1 END) as lbw,
     				COUNT(CASE WHEN w.kind in ('retired hurt', 'retired out') THEN 1 END) as retired_out,
     				COUNT(CASE WHEN w.kind = 'caught and bowled' THEN 1 END)
-				FROM wicket as w JOIN event as e ON w.event = e.id AND e.match_type = @match_type`
-	namedArgs := pgx.NamedArgs{"match_type": matchType}
+				FROM wicket as w JOIN event as e ON w.event = e.id AND %s
+				WHERE %s`, condition, filter.wicketCondition("w"))
 
 	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
 	if err != nil {
@@ -172,59 +232,81 @@ func tournamentWickets(matchType string, dbPool *pgxpool.Pool) (WicketResponse,
 	}
 	wicketInfo, err := pgx.CollectOneRow(rows, pgx.RowToStructByPos[WicketResponse])
 	if err != nil {
-		fmt.Println(wicketInfo, "wicket info here")
 		return WicketResponse{}, err
 	}
 	return wicketInfo, nil
 }
 
-func QueryTournamentStats(appInstance *app.App) (TournamentStatsResponse, error) {
+// QueryTournamentStats returns the stats of every match type along with an aggregated bucket
+// under the key "all". errors of the individual queries are reported inside each bucket.
+func QueryTournamentStats(filter StatsFilter, appInstance *app.App) (map[string]TournamentStatsResponse, error) {
 	dbInstance := pgDB{db: appInstance.DB}
-	matchTypes, err := queryUniqueEvents(dbInstance.db)
+	matchTypes, err := queryUniqueEvents(filter, dbInstance.db)
 	if err != nil {
-		return TournamentStatsResponse{}, err
+		return nil, err
 	}
-	stats := TournamentStatsResponse{}
 
+	response := make(map[string]TournamentStatsResponse)
 	for _, matchType := range matchTypes {
-		appInstance.Logger.Info("fetching total matches")
-		matchCount, err := totalMatches(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching total match", zap.Error(err))
-		}
-		appInstance.Logger.Info("fetching tournament teams count")
-		teamsCount, err := tournamentTeamsCount(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching total teams count", zap.Error(err))
-		}
-		appInstance.Logger.Info("fetching players count")
-		playersCount, err := tournamentPlayersCount(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching total players count", zap.Error(err))
-		}
-		appInstance.Logger.Info("fetching boundary information")
-		boundaries, sixes, err := tournamentBoundariesCount(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching boundary information", zap.Error(err))
-		}
-		appInstance.Logger.Info("fetching wickets info")
-		wicketsInfo, err := tournamentWickets(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching wickets info", zap.Error(err))
-		}
-		appInstance.Logger.Info("fetching balls bowled")
-		ballsBowled, err := tournamentBallsBowled(matchType, dbInstance.db)
-		if err != nil {
-			appInstance.Logger.Info("error in fetching balls bowled", zap.Error(err))
-		}
+		matchTypeFilter := filter
+		matchTypeFilter.MatchType = matchType
+		response[matchType] = matchTypeStats(matchTypeFilter, appInstance)
+	}
+	response[allMatchTypes] = matchTypeStats(filter, appInstance)
+	return response, nil
+}
 
-		stats.Matches = matchCount
-		stats.TeamsCount = teamsCount
-		stats.PlayersCount = playersCount
-		stats.Boundaries = boundaries
-		stats.Sixes = sixes
-		stats.BallsBowled = ballsBowled
-		stats.Wickets = wicketsInfo
+func matchTypeStats(filter StatsFilter, appInstance *app.App) TournamentStatsResponse {
+	dbPool := appInstance.DB
+	stats := TournamentStatsResponse{}
+	addError := func(message string, err error) {
+		appInstance.Logger.Info(message, zap.Any("filter", filter), zap.Error(err))
+		stats.Errors = append(stats.Errors, fmt.Sprintf("%s: %s", message, err.Error()))
+	}
+
+	appInstance.Logger.Info("fetching total matches", zap.String("match_type", filter.MatchType))
+	matchCount, err := totalMatches(filter, dbPool)
+	if err != nil {
+		addError("error in fetching total match", err)
+	}
+	appInstance.Logger.Info("fetching tournament teams count")
+	teamsCount, err := tournamentTeamsCount(filter, dbPool)
+	if err != nil {
+		addError("error in fetching total teams count", err)
 	}
-	return stats, err
+	appInstance.Logger.Info("fetching players count")
+	playersCount, err := tournamentPlayersCount(filter, dbPool)
+	if err != nil {
+		addError("error in fetching total players count", err)
+	}
+	appInstance.Logger.Info("fetching boundary information")
+	boundaries, sixes, err := tournamentBoundariesCount(filter, dbPool)
+	if err != nil {
+		addError("error in fetching boundary information", err)
+	}
+	appInstance.Logger.Info("fetching wickets info")
+	wicketsInfo, err := tournamentWickets(filter, dbPool)
+	if err != nil {
+		addError("error in fetching wickets info", err)
+	}
+	appInstance.Logger.Info("fetching balls bowled")
+	ballsBowled, err := tournamentBallsBowled(filter, dbPool)
+	if err != nil {
+		addError("error in fetching balls bowled", err)
+	}
+	appInstance.Logger.Info("fetching extras")
+	extras, err := tournamentExtras(filter, dbPool)
+	if err != nil {
+		addError("error in fetching extras", err)
+	}
+
+	stats.Matches = matchCount
+	stats.TeamsCount = teamsCount
+	stats.PlayersCount = playersCount
+	stats.Boundaries = boundaries
+	stats.Sixes = sixes
+	stats.BallsBowled = ballsBowled
+	stats.Extras = extras
+	stats.Wickets = wicketsInfo
+	return stats
 }
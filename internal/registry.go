@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidRegistryFile = errors.New("registry file should have identifier and name columns")
+	ErrIdentifierNotFound  = errors.New("no player with the identifier")
+)
+
+// registryBatchSize rows sent to the database in one batch while importing the registry
+const registryBatchSize = 1000
+
+// identifierColumnPrefix columns of people.csv with identifiers of other datasets, eg: key_cricinfo.
+// second identifiers of the same provider are suffixed with _2, eg: key_cricinfo_2
+const identifierColumnPrefix = "key_"
+
+type PlayerIdentifier struct {
+	Provider   string `db:"provider" json:"provider"`
+	Identifier string `db:"identifier" json:"identifier"`
+}
+
+// RegistryImportSummary rows read from the registry files. names of source ids which
+// are not part of any ingested match are skipped as they do not have a player.
+type RegistryImportSummary struct {
+	People       int `json:"people"`
+	Identifiers  int `json:"identifiers"`
+	Names        int `json:"names"`
+	SkippedNames int `json:"skipped_names"`
+}
+
+// ImportRegistry loads the cricsheet people.csv and names.csv into player_identifier and player_alias.
+// either of the paths can be empty to import only one file.
+func ImportRegistry(peoplePath string, namesPath string, appInstance *app.App) (RegistryImportSummary, error) {
+	summary := RegistryImportSummary{}
+	if peoplePath != "" {
+		people, identifiers, err := importPeople(peoplePath, appInstance.DB)
+		if err != nil {
+			return summary, fmt.Errorf("importing %s: %w", peoplePath, err)
+		}
+		summary.People, summary.Identifiers = people, identifiers
+		appInstance.Logger.Info("imported people", zap.Int("people", people), zap.Int("identifiers", identifiers))
+	}
+	if namesPath != "" {
+		names, skipped, err := importNames(namesPath, appInstance.DB)
+		if err != nil {
+			return summary, fmt.Errorf("importing %s: %w", namesPath, err)
+		}
+		summary.Names, summary.SkippedNames = names, skipped
+		appInstance.Logger.Info("imported names", zap.Int("names", names), zap.Int("skipped", skipped))
+	}
+	return summary, nil
+}
+
+// readRegistryFile calls the handler for every row of the csv file, with the header mapping column name to index
+func readRegistryFile(path string, handler func(header map[string]int, row []string) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func(file *os.File) {
+		_ = file.Close()
+	}(file)
+
+	reader := csv.NewReader(file)
+	columns, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	header := make(map[string]int)
+	for index, column := range columns {
+		header[strings.TrimSpace(column)] = index
+	}
+	if _, ok := header["identifier"]; !ok {
+		return ErrInvalidRegistryFile
+	}
+	if _, ok := header["name"]; !ok {
+		return ErrInvalidRegistryFile
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = handler(header, row); err != nil {
+			return err
+		}
+	}
+}
+
+func sendRegistryBatch(batch *pgx.Batch, dbInstance *pgxpool.Pool) error {
+	if batch.Len() == 0 {
+		return nil
+	}
+	return dbInstance.SendBatch(context.Background(), batch).Close()
+}
+
+// importPeople stores identifiers of every person in people.csv, returns the count of people and identifiers
+func importPeople(path string, dbInstance *pgxpool.Pool) (int, int, error) {
+	sqlQuery := `
+		INSERT INTO player_identifier (source_id, provider, identifier)
+		VALUES (@source_id, @provider, @identifier)
+		ON CONFLICT DO NOTHING`
+
+	people, identifiers := 0, 0
+	batch := &pgx.Batch{}
+	err := readRegistryFile(path, func(header map[string]int, row []string) error {
+		people += 1
+		sourceId := row[header["identifier"]]
+		for column, index := range header {
+			if !strings.HasPrefix(column, identifierColumnPrefix) || row[index] == "" {
+				continue
+			}
+			provider := strings.TrimSuffix(strings.TrimPrefix(column, identifierColumnPrefix), "_2")
+			namedArgs := pgx.NamedArgs{"source_id": sourceId, "provider": provider, "identifier": row[index]}
+			batch.Queue(sqlQuery, namedArgs)
+			identifiers += 1
+		}
+		if batch.Len() >= registryBatchSize {
+			err := sendRegistryBatch(batch, dbInstance)
+			batch = &pgx.Batch{}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = sendRegistryBatch(batch, dbInstance); err != nil {
+		return 0, 0, err
+	}
+	return people, identifiers, nil
+}
+
+// importNames stores every name variant of names.csv as an alias of the player with the source_id.
+// returns the count of names stored and skipped.
+func importNames(path string, dbInstance *pgxpool.Pool) (int, int, error) {
+	names := make(map[string][]string)
+	err := readRegistryFile(path, func(header map[string]int, row []string) error {
+		sourceId := row[header["identifier"]]
+		names[sourceId] = append(names[sourceId], row[header["name"]])
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sourceIds := make([]string, 0, len(names))
+	for sourceId := range names {
+		sourceIds = append(sourceIds, sourceId)
+	}
+	playerIds, err := playersBySourceId(sourceIds, dbInstance)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sqlQuery := `
+		INSERT INTO player_alias (player, source_id, name)
+		VALUES (@player, @source_id, @name)
+		ON CONFLICT (source_id, name) DO NOTHING`
+
+	stored, skipped := 0, 0
+	batch := &pgx.Batch{}
+	for sourceId, variants := range names {
+		playerId, ok := playerIds[sourceId]
+		if !ok {
+			skipped += len(variants)
+			continue
+		}
+		for _, name := range variants {
+			batch.Queue(sqlQuery, pgx.NamedArgs{"player": playerId, "source_id": sourceId, "name": name})
+			stored += 1
+		}
+		if batch.Len() >= registryBatchSize {
+			if err = sendRegistryBatch(batch, dbInstance); err != nil {
+				return 0, 0, err
+			}
+			batch = &pgx.Batch{}
+		}
+	}
+	if err = sendRegistryBatch(batch, dbInstance); err != nil {
+		return 0, 0, err
+	}
+	return stored, skipped, nil
+}
+
+// playerIdentifiers identifiers of the player in other datasets through all of its source ids
+func playerIdentifiers(playerId int, dbPool *pgxpool.Pool) ([]PlayerIdentifier, error) {
+	sqlQuery := `
+		SELECT DISTINCT pi.provider, pi.identifier
+		FROM player_identifier AS pi
+		WHERE pi.source_id IN (SELECT pa.source_id FROM player_alias AS pa WHERE pa.player = @player)
+		ORDER BY pi.provider, pi.identifier`
+	namedArgs := pgx.NamedArgs{"player": playerId}
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[PlayerIdentifier])
+}
+
+// QueryPlayerByIdentifier player with the identifier of another dataset, eg: provider cricinfo
+func QueryPlayerByIdentifier(provider string, identifier string, appInstance *app.App) (PlayerIdentity, error) {
+	sqlQuery := `
+		SELECT pa.player
+		FROM player_identifier AS pi JOIN player_alias AS pa ON pa.source_id = pi.source_id
+		WHERE pi.provider = @provider AND pi.identifier = @identifier
+		LIMIT 1`
+	namedArgs := pgx.NamedArgs{"provider": provider, "identifier": identifier}
+
+	var playerId int
+	err := appInstance.DB.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&playerId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PlayerIdentity{}, ErrIdentifierNotFound
+	}
+	if err != nil {
+		return PlayerIdentity{}, err
+	}
+	return playerIdentity(playerId, appInstance.DB)
+}
@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrMatchNotFound = errors.New("match not found")
+
+type BatterScore struct {
+	Name       string  `json:"name"`
+	Runs       int     `json:"runs"`
+	Balls      int     `json:"balls"`
+	Fours      int     `json:"fours"`
+	Sixes      int     `json:"sixes"`
+	StrikeRate float64 `json:"strike_rate"`
+	Dismissal  string  `json:"dismissal"`
+}
+
+type BowlerFigures struct {
+	Name    string  `json:"name"`
+	Overs   string  `json:"overs"`
+	Maidens int     `json:"maidens"`
+	Runs    int     `json:"runs"`
+	Wickets int     `json:"wickets"`
+	Economy float64 `json:"economy"`
+	Dots    int     `json:"dots"`
+	Wides   int     `json:"wides"`
+	NoBalls int     `json:"noballs"`
+}
+
+type InningsScorecard struct {
+	Innings      int             `json:"innings"`
+	Team         string          `json:"team"`
+	Declared     bool            `json:"declared"`
+	Forfeited    bool            `json:"forfeited"`
+	SuperOver    bool            `json:"super_over"`
+	TargetRuns   *int            `json:"target_runs,omitempty"`
+	Batting      []BatterScore   `json:"batting"`
+	Bowling      []BowlerFigures `json:"bowling"`
+	Extras       int             `json:"extras"`
+	ExtrasDetail ExtrasResponse  `json:"extras_detail"`
+	Total        int             `json:"total"`
+	Wickets      int             `json:"wickets"`
+	Overs        string          `json:"overs"`
+}
+
+type ScorecardResponse struct {
+	MatchId int                `json:"match_id"`
+	Event   string             `json:"event"`
+	Date    string             `json:"date"`
+	Venue   string             `json:"venue"`
+	Result  string             `json:"result"`
+	Innings []InningsScorecard `json:"innings"`
+}
+
+type matchEvent struct {
+	ID      int
+	MatchId int
+	Name    string
+	Date    string
+	Venue   string
+	Result  string
+}
+
+type batterScoreRow struct {
+	Innings  int    `db:"innings"`
+	Name     string `db:"name"`
+	Runs     int    `db:"runs"`
+	Balls    int    `db:"balls"`
+	Fours    int    `db:"fours"`
+	Sixes    int    `db:"sixes"`
+	Kind     string `db:"kind"`
+	Bowler   string `db:"bowler"`
+	Fielders string `db:"fielders"`
+}
+
+type bowlerFiguresRow struct {
+	Innings int    `db:"innings"`
+	Name    string `db:"name"`
+	Balls   int    `db:"balls"`
+	Maidens int    `db:"maidens"`
+	Runs    int    `db:"runs"`
+	Wickets int    `db:"wickets"`
+	Dots    int    `db:"dots"`
+	Wides   int    `db:"wides"`
+	NoBalls int    `db:"noballs"`
+}
+
+type inningsTotalRow struct {
+	Innings    int    `db:"innings"`
+	Ordinal    int    `db:"ordinal"`
+	Team       string `db:"team"`
+	Declared   bool   `db:"declared"`
+	Forfeited  bool   `db:"forfeited"`
+	SuperOver  bool   `db:"super_over"`
+	TargetRuns *int   `db:"target_runs"`
+	Extras     int    `db:"extras"`
+	ExtrasResponse
+	Total   int `db:"total"`
+	Wickets int `db:"wickets"`
+	Balls   int `db:"balls"`
+}
+
+// getMatchEvent event of the match. match id of the api is the cricsheet id of the match, stored as file_id,
+// as match_type_number repeats across match types.
+func getMatchEvent(matchId int, dbPool *pgxpool.Pool) (matchEvent, error) {
+	sqlQuery := `
+			SELECT e.id, e.file_id, e.name, TO_CHAR(e.date, 'YYYY-MM-DD'), COALESCE(e.venue, ''), COALESCE(r.summary, '')
+			FROM event AS e LEFT JOIN end_result AS r ON r.event = e.id
+			WHERE e.file_id = @match_id`
+	namedArgs := pgx.NamedArgs{"match_id": matchId}
+
+	var event matchEvent
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(
+		&event.ID, &event.MatchId, &event.Name, &event.Date, &event.Venue, &event.Result)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return matchEvent{}, ErrMatchNotFound
+	}
+	if err != nil {
+		return matchEvent{}, err
+	}
+	return event, nil
+}
+
+// inningsTotals returns the innings of the match in the order they are played.
+// forfeited innings are part of the response without any ball.
+func inningsTotals(eventId int, dbPool *pgxpool.Pool) ([]inningsTotalRow, error) {
+	sqlQuery := fmt.Sprintf(`
+			SELECT
+			    i.id AS innings,
+			    i.ordinal AS ordinal,
+			    t.name AS team,
+			    i.declared AS declared,
+			    i.forfeited AS forfeited,
+			    i.super_over AS super_over,
+			    i.target_runs AS target_runs,
+			    COALESCE(SUM(bi.extra_run), 0) AS extras,%s,
+			    COALESCE(SUM(bi.striker_run + bi.extra_run), 0) AS total,
+			    COALESCE(SUM(bw.wickets), 0) AS wickets,
+			    COUNT(CASE WHEN %s THEN 1 END) AS balls
+			FROM innings AS i
+			    JOIN team AS t ON t.id = i.batting_team
+			    LEFT JOIN ball_info AS bi ON bi.innings = i.id
+			    LEFT JOIN (%s) AS bw ON bw.ball_info = bi.id
+			WHERE i.event = @event
+			GROUP BY i.id, t.name
+			ORDER BY i.ordinal`, extrasColumns, legalBallCondition, ballWicketsQuery)
+	namedArgs := pgx.NamedArgs{"event": eventId}
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[inningsTotalRow])
+}
+
+// battersScore returns every batter who came to the crease, in batting order.
+// batting order is the order in which the batter first appeared as striker or non-striker.
+// a batter with more than one wicket in the innings, eg: retired hurt and out after coming back,
+// is shown with the last of them.
+func battersScore(eventId int, dbPool *pgxpool.Pool) ([]batterScoreRow, error) {
+	sqlQuery := fmt.Sprintf(`
+			WITH appearances AS (
+			    SELECT bi.innings, bi.batsman AS player, bi.id FROM ball_info AS bi WHERE bi.event = @event
+			    UNION ALL
+			    SELECT bi.innings, bi.non_striker, bi.id FROM ball_info AS bi WHERE bi.event = @event
+			), batters AS (
+			    SELECT innings, player, MIN(id) AS first_ball FROM appearances GROUP BY innings, player
+			), scores AS (
+			    SELECT
+			        bi.innings,
+			        bi.batsman AS player,
+			        SUM(bi.striker_run) AS runs,
+			        COUNT(CASE WHEN %s THEN 1 END) AS balls,
+			        COUNT(CASE WHEN bi.striker_run = 4 THEN 1 END) AS fours,
+			        COUNT(CASE WHEN bi.striker_run = 6 THEN 1 END) AS sixes
+			    FROM ball_info AS bi
+			    WHERE bi.event = @event
+			    GROUP BY bi.innings, bi.batsman
+			), dismissals AS (
+			    SELECT DISTINCT ON (bi.innings, w.player)
+			        bi.innings,
+			        w.player,
+			        w.kind,
+			        COALESCE(bp.name, '') AS bowler,
+			        COALESCE((
+			            SELECT STRING_AGG(
+			                CASE WHEN wf.substitute THEN 'sub (' || wf.name || ')' ELSE wf.name END, '/' ORDER BY wf.id
+			            )
+			            FROM wicket_fielder AS wf WHERE wf.wicket = w.id
+			        ), '') AS fielders
+			    FROM ball_info AS bi
+			        JOIN wicket AS w ON w.ball_info = bi.id
+			        LEFT JOIN player AS bp ON bp.id = w.bowler
+			    WHERE bi.event = @event
+			    ORDER BY bi.innings, w.player, bi.id DESC, w.id DESC
+			)
+			SELECT
+			    b.innings AS innings,
+			    p.name AS name,
+			    COALESCE(s.runs, 0) AS runs,
+			    COALESCE(s.balls, 0) AS balls,
+			    COALESCE(s.fours, 0) AS fours,
+			    COALESCE(s.sixes, 0) AS sixes,
+			    COALESCE(d.kind, '') AS kind,
+			    COALESCE(d.bowler, '') AS bowler,
+			    COALESCE(d.fielders, '') AS fielders
+			FROM batters AS b
+			    JOIN player AS p ON p.id = b.player
+			    LEFT JOIN scores AS s ON s.innings = b.innings AND s.player = b.player
+			    LEFT JOIN dismissals AS d ON d.innings = b.innings AND d.player = b.player
+			ORDER BY b.first_ball`, ballFacedCondition)
+	namedArgs := pgx.NamedArgs{"event": eventId}
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[batterScoreRow])
+}
+
+// bowlersFigures returns the figures of every bowler in the order they came to bowl.
+// byes and leg byes are not charged to the bowler.
+func bowlersFigures(eventId int, dbPool *pgxpool.Pool) ([]bowlerFiguresRow, error) {
+	sqlQuery := fmt.Sprintf(`
+			WITH overs AS (
+			    SELECT
+			        bi.innings,
+			        bi.bowler,
+			        COUNT(CASE WHEN %[1]s THEN 1 END) AS legal_balls,
+			        SUM(bi.striker_run + bi.wides + bi.noballs) AS runs
+			    FROM ball_info AS bi
+			    WHERE bi.event = @event
+			    GROUP BY bi.innings, bi.bowler, bi.over
+			), maidens AS (
+			    SELECT innings, bowler, COUNT(*) AS maidens
+			    FROM overs
+			    WHERE legal_balls = @balls_per_over AND runs = 0
+			    GROUP BY innings, bowler
+			)
+			SELECT
+			    bi.innings AS innings,
+			    p.name AS name,
+			    COUNT(CASE WHEN %[1]s THEN 1 END) AS balls,
+			    COALESCE(MAX(m.maidens), 0) AS maidens,
+			    COALESCE(SUM(bi.striker_run + bi.wides + bi.noballs), 0) AS runs,
+			    COALESCE(SUM(bw.bowler_wickets), 0) AS wickets,
+			    COUNT(CASE WHEN %[1]s AND bi.striker_run = 0 THEN 1 END) AS dots,
+			    COUNT(CASE WHEN bi.wides > 0 THEN 1 END) AS wides,
+			    COUNT(CASE WHEN bi.noballs > 0 THEN 1 END) AS noballs
+			FROM ball_info AS bi
+			    JOIN player AS p ON p.id = bi.bowler
+			    LEFT JOIN (%[2]s) AS bw ON bw.ball_info = bi.id
+			    LEFT JOIN maidens AS m ON m.innings = bi.innings AND m.bowler = bi.bowler
+			WHERE bi.event = @event
+			GROUP BY bi.innings, bi.bowler, p.name
+			ORDER BY MIN(bi.id)`, legalBallCondition, ballWicketsQuery)
+	namedArgs := pgx.NamedArgs{"event": eventId, "balls_per_over": ballsPerOver}
+
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[bowlerFiguresRow])
+}
+
+// dismissalText scorecard notation of the dismissal. fielders are separated by "/" when
+// more than one fielder is involved, eg: run out (Jadeja/Dhoni)
+func dismissalText(kind string, bowler string, fielders string) string {
+	switch kind {
+	case "":
+		return "not out"
+	case "bowled":
+		return "b " + bowler
+	case "caught and bowled":
+		return "c & b " + bowler
+	case "caught":
+		if fielders == "" {
+			return "caught b " + bowler
+		}
+		return "c " + fielders + " b " + bowler
+	case "stumped":
+		if fielders == "" {
+			return "stumped b " + bowler
+		}
+		return "st " + fielders + " b " + bowler
+	case "lbw", "hit wicket":
+		return kind + " b " + bowler
+	case "run out":
+		if fielders == "" {
+			return kind
+		}
+		return "run out (" + fielders + ")"
+	default:
+		// run out, retired, obstructing the field etc. are not credited to the bowler
+		return kind
+	}
+}
+
+// QueryScorecard batting scorecard and bowling figures of every innings of the match
+func QueryScorecard(matchId int, appInstance *app.App) (ScorecardResponse, error) {
+	event, err := getMatchEvent(matchId, appInstance.DB)
+	if err != nil {
+		return ScorecardResponse{}, err
+	}
+	totals, err := inningsTotals(event.ID, appInstance.DB)
+	if err != nil {
+		return ScorecardResponse{}, err
+	}
+	batters, err := battersScore(event.ID, appInstance.DB)
+	if err != nil {
+		return ScorecardResponse{}, err
+	}
+
+	bowlers, err := bowlersFigures(event.ID, appInstance.DB)
+	if err != nil {
+		return ScorecardResponse{}, err
+	}
+
+	battingByInnings := make(map[int][]BatterScore)
+	for _, batter := range batters {
+		battingByInnings[batter.Innings] = append(battingByInnings[batter.Innings], BatterScore{
+			Name:       batter.Name,
+			Runs:       batter.Runs,
+			Balls:      batter.Balls,
+			Fours:      batter.Fours,
+			Sixes:      batter.Sixes,
+			StrikeRate: strikeRate(batter.Runs, batter.Balls),
+			Dismissal:  dismissalText(batter.Kind, batter.Bowler, batter.Fielders),
+		})
+	}
+
+	bowlingByInnings := make(map[int][]BowlerFigures)
+	for _, bowler := range bowlers {
+		bowlingByInnings[bowler.Innings] = append(bowlingByInnings[bowler.Innings], BowlerFigures{
+			Name:    bowler.Name,
+			Overs:   oversText(bowler.Balls),
+			Maidens: bowler.Maidens,
+			Runs:    bowler.Runs,
+			Wickets: bowler.Wickets,
+			Economy: economy(bowler.Runs, bowler.Balls),
+			Dots:    bowler.Dots,
+			Wides:   bowler.Wides,
+			NoBalls: bowler.NoBalls,
+		})
+	}
+
+	response := ScorecardResponse{
+		MatchId: event.MatchId,
+		Event:   event.Name,
+		Date:    event.Date,
+		Venue:   event.Venue,
+		Result:  event.Result,
+		Innings: make([]InningsScorecard, 0, len(totals)),
+	}
+	for _, total := range totals {
+		response.Innings = append(response.Innings, InningsScorecard{
+			Innings:      total.Ordinal,
+			Team:         total.Team,
+			Declared:     total.Declared,
+			Forfeited:    total.Forfeited,
+			SuperOver:    total.SuperOver,
+			TargetRuns:   total.TargetRuns,
+			Batting:      battingByInnings[total.Innings],
+			Bowling:      bowlingByInnings[total.Innings],
+			Extras:       total.Extras,
+			ExtrasDetail: total.ExtrasResponse,
+			Total:        total.Total,
+			Wickets:      total.Wickets,
+			Overs:        oversText(total.Balls),
+		})
+	}
+	return response, nil
+}
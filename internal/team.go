@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrTeamNotFound = errors.New("team not found")
+
+type InningsTotal struct {
+	Runs     int    `json:"runs"`
+	Wickets  int    `json:"wickets"`
+	Overs    string `json:"overs"`
+	MatchId  int    `json:"match_id"`
+	Opponent string `json:"opponent"`
+	Date     string `json:"date"`
+	Link     string `json:"link"`
+}
+
+// TeamRecord ties won and lost are the tied matches decided by a super over or bowl out
+type TeamRecord struct {
+	Matches        int            `json:"matches"`
+	Won            int            `json:"won"`
+	Lost           int            `json:"lost"`
+	Tied           int            `json:"tied"`
+	TiesWon        int            `json:"ties_won"`
+	TiesLost       int            `json:"ties_lost"`
+	Drawn          int            `json:"drawn"`
+	NoResult       int            `json:"no_result"`
+	HighestTotal   *InningsTotal  `json:"highest_total"`
+	LowestTotal    *InningsTotal  `json:"lowest_total"`
+	ExtrasConceded ExtrasResponse `json:"extras_conceded"`
+}
+
+type TeamRecordResponse struct {
+	TeamId     int                   `json:"team_id"`
+	Name       string                `json:"name"`
+	Overall    TeamRecord            `json:"overall"`
+	MatchTypes map[string]TeamRecord `json:"match_types"`
+}
+
+type teamResultRow struct {
+	MatchType string `db:"match_type"`
+	Matches   int    `db:"matches"`
+	Won       int    `db:"won"`
+	Lost      int    `db:"lost"`
+	Tied      int    `db:"tied"`
+	TiesWon   int    `db:"ties_won"`
+	TiesLost  int    `db:"ties_lost"`
+	Drawn     int    `db:"drawn"`
+	NoResult  int    `db:"no_result"`
+}
+
+type teamExtrasRow struct {
+	MatchType string `db:"match_type"`
+	ExtrasResponse
+}
+
+type teamInningsRow struct {
+	MatchType string `db:"match_type"`
+	MatchId   int    `db:"match_id"`
+	Date      string `db:"date"`
+	Opponent  string `db:"opponent"`
+	Runs      int    `db:"runs"`
+	Wickets   int    `db:"wickets"`
+	Balls     int    `db:"balls"`
+	Completed bool   `db:"completed"`
+}
+
+func getTeamName(teamId int, dbPool *pgxpool.Pool) (string, error) {
+	sqlQuery := `SELECT name FROM team WHERE id = @team_id`
+	namedArgs := pgx.NamedArgs{"team_id": teamId}
+
+	var name string
+	err := dbPool.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&name)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrTeamNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func teamResults(teamId int, filter StatsFilter, dbPool *pgxpool.Pool) ([]teamResultRow, error) {
+	sqlQuery, namedArgs := teamResultsQuery(teamId, filter)
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[teamResultRow])
+}
+
+// teamResultsQuery team id is passed as @team_id, @team is the team name of the filter
+func teamResultsQuery(teamId int, filter StatsFilter) (string, pgx.NamedArgs) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			SELECT
+			    e.match_type AS match_type,
+			    COUNT(*) AS matches,
+			    COUNT(CASE WHEN r.team_won = @team_id THEN 1 END) AS won,
+			    COUNT(CASE WHEN r.team_won <> @team_id THEN 1 END) AS lost,
+			    COUNT(CASE WHEN r.result = 'tie' THEN 1 END) AS tied,
+			    COUNT(CASE WHEN r.result = 'tie' AND COALESCE(r.eliminator, r.bowl_out) = @team_id THEN 1 END) AS ties_won,
+			    COUNT(CASE WHEN r.result = 'tie' AND COALESCE(r.eliminator, r.bowl_out) <> @team_id THEN 1 END) AS ties_lost,
+			    COUNT(CASE WHEN r.result = 'draw' THEN 1 END) AS drawn,
+			    COUNT(CASE WHEN r.result = 'no result' THEN 1 END) AS no_result
+			FROM event AS e
+			    LEFT JOIN end_result AS r ON r.event = e.id
+			WHERE @team_id IN (e.team_a, e.team_b) AND %s
+			GROUP BY e.match_type`, condition)
+	namedArgs["team_id"] = teamId
+	return sqlQuery, namedArgs
+}
+
+// teamExtrasConceded extras given away by the team while bowling
+func teamExtrasConceded(teamId int, filter StatsFilter, dbPool *pgxpool.Pool) ([]teamExtrasRow, error) {
+	sqlQuery, namedArgs := teamExtrasQuery(teamId, filter)
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[teamExtrasRow])
+}
+
+func teamExtrasQuery(teamId int, filter StatsFilter) (string, pgx.NamedArgs) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			SELECT e.match_type AS match_type, %s
+			FROM ball_info AS bi
+			    JOIN event AS e ON e.id = bi.event AND %s
+			WHERE @team_id IN (e.team_a, e.team_b) AND bi.batting_team <> @team_id AND %s
+			GROUP BY e.match_type`, extrasColumns, condition, filter.ballCondition("bi"))
+	namedArgs["team_id"] = teamId
+	return sqlQuery, namedArgs
+}
+
+// add sums up the extras
+func (extras ExtrasResponse) add(other ExtrasResponse) ExtrasResponse {
+	return ExtrasResponse{
+		Wides:   extras.Wides + other.Wides,
+		NoBalls: extras.NoBalls + other.NoBalls,
+		Byes:    extras.Byes + other.Byes,
+		LegByes: extras.LegByes + other.LegByes,
+		Penalty: extras.Penalty + other.Penalty,
+	}
+}
+
+// teamInnings every innings batted by the team, super overs are part of it only when the filter
+// includes them. an innings is completed when the team is all out or has played its full quota of overs.
+func teamInnings(teamId int, filter StatsFilter, dbPool *pgxpool.Pool) ([]teamInningsRow, error) {
+	sqlQuery, namedArgs := teamInningsQuery(teamId, filter)
+	rows, err := dbPool.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowToStructByName[teamInningsRow])
+}
+
+func teamInningsQuery(teamId int, filter StatsFilter) (string, pgx.NamedArgs) {
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			WITH team_innings AS (
+			    SELECT
+			        bi.event,
+			        SUM(bi.striker_run + bi.extra_run) AS runs,
+			        COALESCE(SUM(bw.wickets), 0) AS wickets,
+			        COUNT(CASE WHEN %s THEN 1 END) AS balls
+			    FROM ball_info AS bi
+			        LEFT JOIN (%s) AS bw ON bw.ball_info = bi.id
+			    WHERE bi.batting_team = @team_id AND %s
+			    GROUP BY bi.event, bi.innings
+			)
+			SELECT
+			    e.match_type AS match_type,
+			    e.file_id AS match_id,
+			    TO_CHAR(e.date, 'YYYY-MM-DD') AS date,
+			    t.name AS opponent,
+			    i.runs AS runs,
+			    i.wickets AS wickets,
+			    i.balls AS balls,
+			    (i.wickets >= 10 OR (e.overs > 0 AND i.balls >= e.overs * @balls_per_over)) AS completed
+			FROM team_innings AS i
+			    JOIN event AS e ON e.id = i.event AND %s
+			    JOIN team AS t ON t.id = CASE WHEN e.team_a = @team_id THEN e.team_b ELSE e.team_a END`,
+		legalBallCondition, ballWicketsQuery, filter.ballCondition("bi"), condition,
+	)
+	namedArgs["team_id"] = teamId
+	namedArgs["balls_per_over"] = ballsPerOver
+	return sqlQuery, namedArgs
+}
+
+func (row teamInningsRow) inningsTotal() *InningsTotal {
+	return &InningsTotal{
+		Runs:     row.Runs,
+		Wickets:  row.Wickets,
+		Overs:    oversText(row.Balls),
+		MatchId:  row.MatchId,
+		Opponent: row.Opponent,
+		Date:     row.Date,
+		Link:     fmt.Sprintf("/v1/cricket/match/%d/scorecard", row.MatchId),
+	}
+}
+
+// addInnings keeps the highest total and the lowest completed total in the record
+func (record *TeamRecord) addInnings(row teamInningsRow) {
+	if record.HighestTotal == nil || row.Runs > record.HighestTotal.Runs {
+		record.HighestTotal = row.inningsTotal()
+	}
+	if row.Completed && (record.LowestTotal == nil || row.Runs < record.LowestTotal.Runs) {
+		record.LowestTotal = row.inningsTotal()
+	}
+}
+
+// QueryTeamRecord overall and per match type results of the team along with the highest and lowest totals
+func QueryTeamRecord(teamId int, filter StatsFilter, appInstance *app.App) (TeamRecordResponse, error) {
+	name, err := getTeamName(teamId, appInstance.DB)
+	if err != nil {
+		return TeamRecordResponse{}, err
+	}
+	results, err := teamResults(teamId, filter, appInstance.DB)
+	if err != nil {
+		return TeamRecordResponse{}, err
+	}
+	innings, err := teamInnings(teamId, filter, appInstance.DB)
+	if err != nil {
+		return TeamRecordResponse{}, err
+	}
+	extras, err := teamExtrasConceded(teamId, filter, appInstance.DB)
+	if err != nil {
+		return TeamRecordResponse{}, err
+	}
+
+	response := TeamRecordResponse{TeamId: teamId, Name: name, MatchTypes: make(map[string]TeamRecord)}
+	for _, result := range results {
+		response.MatchTypes[result.MatchType] = TeamRecord{
+			Matches:  result.Matches,
+			Won:      result.Won,
+			Lost:     result.Lost,
+			Tied:     result.Tied,
+			TiesWon:  result.TiesWon,
+			TiesLost: result.TiesLost,
+			Drawn:    result.Drawn,
+			NoResult: result.NoResult,
+		}
+		response.Overall.Matches += result.Matches
+		response.Overall.Won += result.Won
+		response.Overall.Lost += result.Lost
+		response.Overall.Tied += result.Tied
+		response.Overall.TiesWon += result.TiesWon
+		response.Overall.TiesLost += result.TiesLost
+		response.Overall.Drawn += result.Drawn
+		response.Overall.NoResult += result.NoResult
+	}
+	for _, row := range innings {
+		record := response.MatchTypes[row.MatchType]
+		record.addInnings(row)
+		response.MatchTypes[row.MatchType] = record
+		response.Overall.addInnings(row)
+	}
+	for _, row := range extras {
+		record := response.MatchTypes[row.MatchType]
+		record.ExtrasConceded = row.ExtrasResponse
+		response.MatchTypes[row.MatchType] = record
+		response.Overall.ExtrasConceded = response.Overall.ExtrasConceded.add(row.ExtrasResponse)
+	}
+	return response, nil
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// teamFilterArg position of the team name of the filter in the rewritten query
+var teamFilterArg = regexp.MustCompile(`ft\.name = \$(\d+)`)
+
+func TestTeamQueriesWithTeamFilter(t *testing.T) {
+	teamId := 7
+	filter := StatsFilter{Team: "India", MatchType: "ODI"}
+	queries := map[string]func(int, StatsFilter) (string, pgx.NamedArgs){
+		"results": teamResultsQuery,
+		"extras":  teamExtrasQuery,
+		"innings": teamInningsQuery,
+	}
+	for name, query := range queries {
+		t.Run(name, func(t *testing.T) {
+			sqlQuery, namedArgs := query(teamId, filter)
+			// strict args fail when the query uses an arg which is not passed or an arg is not used
+			rewritten, args, err := pgx.StrictNamedArgs(namedArgs).RewriteQuery(context.Background(), nil, sqlQuery, nil)
+			if err != nil {
+				t.Fatalf("rewriting query: %v", err)
+			}
+			if !slices.Contains(args, any(teamId)) {
+				t.Errorf("args = %v, want the team id %d", args, teamId)
+			}
+			match := teamFilterArg.FindStringSubmatch(rewritten)
+			if match == nil {
+				t.Fatalf("query does not have the team filter: %s", rewritten)
+			}
+			position, _ := strconv.Atoi(match[1])
+			if args[position-1] != "India" {
+				t.Errorf("team filter arg = %v, want India", args[position-1])
+			}
+		})
+	}
+}
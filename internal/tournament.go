@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"cricket/cmd/app"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type TournamentResponse struct {
+	Name      string   `db:"name" json:"name"`
+	Season    string   `db:"season" json:"season"`
+	MatchType string   `db:"match_type" json:"match_type"`
+	Gender    string   `db:"gender" json:"gender"`
+	StartDate string   `db:"start_date" json:"start_date"`
+	EndDate   string   `db:"end_date" json:"end_date"`
+	Teams     []string `db:"teams" json:"teams"`
+	Matches   int      `db:"matches" json:"matches"`
+}
+
+type TournamentListResponse struct {
+	Tournaments []TournamentResponse `json:"tournaments"`
+	Page        int                  `json:"page"`
+	PageSize    int                  `json:"page_size"`
+	Total       int                  `json:"total"`
+}
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// tournamentSortColumns allowed values of sort_by mapped to the columns of the listing query.
+// sort column cannot be passed as named arg, so only these values are added to the query.
+var tournamentSortColumns = map[string]string{
+	"name":       "name",
+	"season":     "season",
+	"match_type": "match_type",
+	"start_date": "start_date",
+	"end_date":   "end_date",
+	"matches":    "matches",
+}
+
+// QueryTournaments lists the tournaments from the event table. a tournament is a distinct
+// event name played in a season for a match type and gender.
+func QueryTournaments(
+	filter StatsFilter, pagination Pagination, sortBy string, order string, appInstance *app.App,
+) (TournamentListResponse, error) {
+	sortColumn, ok := tournamentSortColumns[sortBy]
+	if !ok {
+		return TournamentListResponse{}, fmt.Errorf("%w: sort_by %q", ErrInvalidSort, sortBy)
+	}
+	order = strings.ToUpper(order)
+	if order != "ASC" && order != "DESC" {
+		return TournamentListResponse{}, fmt.Errorf("%w: order %q", ErrInvalidSort, order)
+	}
+	pagination = pagination.normalise()
+
+	condition, namedArgs := filter.eventCondition("e")
+	sqlQuery := fmt.Sprintf(`
+			SELECT
+			    e.name AS name,
+			    COALESCE(e.season, '') AS season,
+			    e.match_type AS match_type,
+			    COALESCE(e.gender, '') AS gender,
+			    TO_CHAR(MIN(e.date), 'YYYY-MM-DD') AS start_date,
+			    TO_CHAR(MAX(e.date), 'YYYY-MM-DD') AS end_date,
+			    ARRAY(SELECT DISTINCT UNNEST(ARRAY_AGG(ta.name) || ARRAY_AGG(tb.name)) ORDER BY 1) AS teams,
+			    COUNT(*) AS matches
+			FROM event AS e
+			    JOIN team AS ta ON ta.id = e.team_a
+			    JOIN team AS tb ON tb.id = e.team_b
+			WHERE %s
+			GROUP BY e.name, COALESCE(e.season, ''), e.match_type, COALESCE(e.gender, '')
+			ORDER BY %s %s, name
+			LIMIT @limit OFFSET @offset`, condition, sortColumn, order)
+	namedArgs["limit"] = pagination.PageSize
+	namedArgs["offset"] = pagination.offset()
+
+	rows, err := appInstance.DB.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return TournamentListResponse{}, err
+	}
+	tournaments, err := pgx.CollectRows(rows, pgx.RowToStructByName[TournamentResponse])
+	if err != nil {
+		return TournamentListResponse{}, err
+	}
+
+	// total is counted separately, so that a page after the last one still has the total
+	countQuery := fmt.Sprintf(`
+			SELECT COUNT(*) FROM (
+			    SELECT 1
+			    FROM event AS e
+			        JOIN team AS ta ON ta.id = e.team_a
+			        JOIN team AS tb ON tb.id = e.team_b
+			    WHERE %s
+			    GROUP BY e.name, COALESCE(e.season, ''), e.match_type, COALESCE(e.gender, '')
+			) AS tournament`, condition)
+	var total int
+	err = appInstance.DB.QueryRow(context.TODO(), countQuery, namedArgs).Scan(&total)
+	if err != nil {
+		return TournamentListResponse{}, err
+	}
+
+	return TournamentListResponse{
+		Tournaments: tournaments,
+		Page:        pagination.Page,
+		PageSize:    pagination.PageSize,
+		Total:       total,
+	}, nil
+}
@@ -6,10 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgerrcode"
@@ -41,306 +40,755 @@ type eventSql struct {
 	Toss          string
 	Overs         int
 	MatchType     string
+	Season        string
+	Gender        string
+	TeamType      string
 }
 
 type wicketSql struct {
-	player int
-	kind   string
-	bowler int
-	event  int
+	player   int
+	kind     string
+	bowler   int
+	event    int
+	ballInfo int
+	fielders []fielderSql
 }
 
+// fielderSql player is 0 when the fielder is not part of playing 11, eg: substitutes
+type fielderSql struct {
+	player     int
+	name       string
+	substitute bool
+}
+
+// inningsSql target is stored only when TargetRuns is set. TargetRevised is set when the target
+// is revised by rain rules like D/L, cricsheet files have the revised target.
+type inningsSql struct {
+	Event         int
+	Ordinal       int
+	BattingTeam   int
+	TargetRuns    int
+	TargetOvers   float64
+	TargetRevised bool
+	Declared      bool
+	Forfeited     bool
+	SuperOver     bool
+}
+
+type endResultSql struct {
+	Event            int
+	Result           string
+	Summary          string
+	TeamWon          int
+	WinByRuns        int
+	WinByWickets     int
+	WinByInnings     int
+	Method           string
+	Eliminator       int
+	BowlOut          int
+	TeamAScore       int
+	TeamAWickets     int
+	TeamBScore       int
+	TeamBWickets     int
+	PlayerOfTheMatch int
+}
+
+// ballInfoSql Ball is the index of the delivery in the over and Delivery is the sequence number
+// of the delivery in the innings
 type ballInfoSql struct {
 	Event       int
+	Innings     int
 	Over        int
 	Ball        int
+	Delivery    int
+	LegalBall   int
+	IsLegal     bool
+	OverLabel   string
 	BattingTeam int
 	Batsman     int
 	Bowler      int
 	NonStriker  int
 	StrikerRun  int
 	ExtraRun    int
-	Wickets     int
+	Wides       int
+	NoBalls     int
+	Byes        int
+	LegByes     int
+	Penalty     int
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 }
 
-func ReadData(service *app.App) {
-	directoryName := "t20s_male_json"
-	files, err := os.ReadDir(directoryName)
+// ReadData ingests the match files of the inputs in config with config.Workers matches in parallel.
+// progress is logged after every file. error is returned when the inputs have no match files.
+func ReadData(config IngestConfig, service *app.App) error {
+	files, closeArchives, err := config.matchFiles()
 	if err != nil {
-		panic("error in reading json directory")
+		service.Logger.Info("error in reading input files", zap.Strings("inputs", config.Inputs), zap.Error(err))
+		return err
 	}
+	defer closeArchives()
 
-	skippedItems := 0
-	parsedMatchesBrokenCount := 0
-	for _, e := range files {
-		jsonFilePath := fmt.Sprintf("%s/%s", directoryName, e.Name())
-		content, err := os.ReadFile(jsonFilePath)
-		if strings.Contains(jsonFilePath, "README.txt") {
-			// ignore readme file
-			continue
-		}
-		breakParsing := false
+	workers := max(config.Workers, 1)
+	if maxConns := int(service.DB.Config().MaxConns); workers > maxConns {
+		service.Logger.Info(
+			"workers are more than the database connections, workers will wait for connections",
+			zap.Int("workers", workers),
+			zap.Int("max connections", maxConns),
+		)
+	}
 
-		if err != nil {
-			service.Logger.Info(
-				"error in fetching data",
-				zap.String("file path", jsonFilePath),
-				zap.Error(err),
-			)
-			//continue
-			panic("err")
+	state := newIngestState(config)
+	jobs := make(chan matchFile)
+	results := make(chan ingestResult)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				results <- ingestFile(file, state, service)
+			}
+		}()
+	}
+	go func() {
+		for _, file := range files {
+			if state.limitReached() {
+				service.Logger.Info("reached the limit of matches", zap.Int("limit", config.Limit))
+				break
+			}
+			jobs <- file
 		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
 
-		var jsonData baseStruct
+	processed := 0
+	counts := make(map[ingestStatus]int)
+	for result := range results {
+		processed += 1
+		counts[result.status] += 1
+		service.Logger.Info(
+			"processed file",
+			zap.String("file", result.path),
+			zap.String("status", string(result.status)),
+			zap.Int("file id", result.fileId),
+			zap.Int("processed", processed),
+			zap.Int("total", len(files)),
+			zap.Error(result.err),
+		)
+	}
+	fmt.Println(
+		"ingested items", counts[statusIngested],
+		"skipped items", counts[statusSkipped],
+		"filtered items", counts[statusFiltered],
+		"existing items", counts[statusExists],
+		"over the limit items", counts[statusLimit],
+		"ignored items", counts[statusIgnored],
+		"failed items", counts[statusFailed],
+	)
+	return nil
+}
+
+// ingestFile saves the match of the file, it is called concurrently by the workers of ReadData
+func ingestFile(file matchFile, state *ingestState, service *app.App) ingestResult {
+	jsonFilePath := file.path
+	content, err := file.read()
+	if strings.Contains(jsonFilePath, "README.txt") {
+		// ignore readme file
+		return ingestResult{path: jsonFilePath, status: statusIgnored}
+	}
+	if err != nil {
+		service.Logger.Info(
+			"error in fetching data",
+			zap.String("file path", jsonFilePath),
+			zap.Error(err),
+		)
+		return ingestResult{path: jsonFilePath, status: statusFailed, err: err}
+	}
+
+	var jsonData baseStruct
+	switch {
+	case isYamlFile(jsonFilePath):
+		jsonData, err = decodeYamlMatch(content)
+	case isCsvFile(jsonFilePath):
+		var info []byte
+		if file.readInfo != nil {
+			info, err = file.readInfo()
+		}
+		if err == nil {
+			jsonData, err = decodeCsvMatch(content, info)
+		}
+	default:
 		err = json.Unmarshal(content, &jsonData)
+	}
+	if err == nil {
+		err = checkMatchInfo(jsonData.Info)
+	}
+	if err != nil {
+		service.Logger.Info(
+			"error in unmarshalling match data",
+			zap.Error(err),
+			zap.String("file", jsonFilePath),
+		)
+		return ingestResult{path: jsonFilePath, status: statusSkipped, err: err}
+	}
+
+	// match_type_number repeats across match types and is missing for domestic matches,
+	// matches are identified by the cricsheet id in the file name, eg: 1234.json
+	fileId, err := matchFileId(jsonFilePath)
+	if err != nil {
+		service.Logger.Info("error in reading match id from file name", zap.String("file", jsonFilePath), zap.Error(err))
+		return ingestResult{path: jsonFilePath, status: statusSkipped, err: err}
+	}
+
+	if !state.config.allowsMatch(jsonData.Info) {
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFiltered}
+	}
+
+	exists := isMatchDataExists(fileId, service)
+	if exists {
+		service.Logger.Info("skipping file as it is already exists", zap.String("filename", jsonFilePath))
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusExists}
+	}
+	if len(jsonData.Info.Registry.People) == 0 {
+		// older yaml files do not have the registry, their players are resolved by name
+		jsonData.Info.Registry, err = registryFromAliases(jsonData.Info.Players, service.DB)
 		if err != nil {
-			service.Logger.Info(
-				"error in unmarshalling json data",
-				zap.Error(err),
-				zap.String("file", jsonFilePath),
-			)
-			skippedItems += 1
-			continue
+			service.Logger.Info("error in resolving players without registry", zap.String("file", jsonFilePath), zap.Error(err))
+			return ingestResult{path: jsonFilePath, fileId: fileId, status: statusSkipped, err: err}
 		}
+	}
+	if status := state.claim(fileId); status != "" {
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: status}
+	}
+	service.Logger.Info("successfully unmarshalled file", zap.String("file", jsonFilePath))
 
-		exists := isMatchDataExists(jsonData.Info.MatchTypeNumber, service)
-		if exists {
-			service.Logger.Info("skipping file as it is already exists", zap.String("filename", jsonFilePath))
-			continue
-		}
-		service.Logger.Info("successfully unmarshalled file", zap.String("file", jsonFilePath))
+	service.Logger.Info("calling save team function", zap.Any("teams", jsonData.Info.Teams))
+	teamInfo, err := saveTeam(jsonData.Info.Teams, service.DB)
+	if err != nil {
+		service.Logger.Info(
+			"error in saving team",
+			zap.Error(err),
+			zap.Any("teams", jsonData.Info.Teams),
+			zap.Int("file id", fileId))
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
+	}
+
+	service.Logger.Info(
+		"get or create team",
+		zap.Int("file id", fileId),
+		zap.Any("teams", jsonData.Info.Teams),
+		zap.Any("saved or read team id", teamInfo),
+	)
 
-		service.Logger.Info("calling save team function", zap.Any("teams", jsonData.Info.Teams))
-		teamInfo, err := saveTeam(jsonData.Info.Teams, service.DB)
+	playerAliases := make(map[string]string)
+	for teamName, players := range jsonData.Info.Players {
+		playersMapping := make(map[string]string)
+		for _, player := range players {
+			sourceId := jsonData.Info.Registry.People[player]
+			if sourceId == "" {
+				continue
+			}
+			playersMapping[sourceId] = displayName(player)
+			playerAliases[player] = sourceId
+		}
+		err := savePlayersBulk(playersMapping, teamInfo[teamName], service.DB)
 		if err != nil {
 			service.Logger.Info(
-				"error in saving team",
+				"error in saving players",
 				zap.Error(err),
-				zap.Any("teams", jsonData.Info.Teams),
-				zap.Int("match_id", jsonData.Info.MatchTypeNumber))
-			panic("error in saving team!!!!")
+				zap.String("team", teamName),
+				zap.Int("file id", fileId),
+			)
+			return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
 		}
+	}
+	err = saveAliasesBulk(playerAliases, service.DB)
+	if err != nil {
+		service.Logger.Info(
+			"error in saving player aliases",
+			zap.Error(err),
+			zap.Int("file id", fileId),
+		)
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
+	}
 
+	teamPlayers, err := resolveMatchPlayers(jsonData.Info.Registry, service.DB)
+	if err != nil {
 		service.Logger.Info(
-			"get or create team",
-			zap.Int("match_id", jsonData.Info.MatchTypeNumber),
-			zap.Any("teams", jsonData.Info.Teams),
-			zap.Any("saved or read team id", teamInfo),
+			"error in resolving players of the match",
+			zap.Error(err),
+			zap.Int("file id", fileId),
 		)
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
+	}
+	teamPlayersId := make(map[int][]int)
+	for teamName, players := range jsonData.Info.Players {
+		teamId := teamInfo[teamName]
+		for _, player := range players {
+			if playerId, ok := teamPlayers[player]; ok {
+				teamPlayersId[teamId] = append(teamPlayersId[teamId], playerId)
+			}
+		}
+	}
 
-		teamPlayersId := make(map[int][]int)
-		for teamName, players := range jsonData.Info.Players {
-			playersMapping := make(map[string]string)
+	tossAsString := fmt.Sprintf(
+		"%v won the toss and chose to %v", jsonData.Info.Toss["winner"], jsonData.Info.Toss["decision"])
 
-			sourceIds := make([]string, 0)
-			for _, player := range players {
-				if strings.Contains(player, "(2)") || strings.Contains(player, "(3)") {
-					breakParsing = true
-					break
-				}
-				sourceId := jsonData.Info.Registry.People[player]
-				playersMapping[sourceId] = player
-				sourceIds = append(sourceIds, sourceId)
-			}
-			if breakParsing {
-				break
-			}
-			teamId := teamInfo[teamName]
-			err := savePlayersBulk(playersMapping, teamId, service.DB)
-			if err != nil {
-				log.Printf("error in saving players: %s", err.Error())
-				return
-			}
+	eventData := eventSql{
+		FileId:        fileId,
+		MatchId:       jsonData.Info.MatchTypeNumber,
+		Name:          jsonData.Info.MatchEvent.Name,
+		Date:          jsonData.Info.Dates[0],
+		TeamA:         teamInfo[jsonData.Info.Teams[0]],
+		TeamB:         teamInfo[jsonData.Info.Teams[1]],
+		PlayingXiAIds: teamPlayersId[teamInfo[jsonData.Info.Teams[0]]],
+		PlayingXiBIds: teamPlayersId[teamInfo[jsonData.Info.Teams[1]]],
+		Venue:         jsonData.Info.Venue,
+		Toss:          tossAsString, // adding it as a string for now.
+		Overs:         jsonData.Info.Overs,
+		MatchType:     jsonData.Info.MatchType,
+		Season:        seasonAsString(jsonData.Info.Season),
+		Gender:        jsonData.Info.Gender,
+		TeamType:      jsonData.Info.TeamType,
+	}
 
-			playerIds, err := getPlayersBasedOnSourceId(sourceIds, service.DB)
-			if err != nil {
-				service.Logger.Info(
-					"error in getting players based on source id",
-					zap.Error(err),
-					zap.String("team name", teamName),
-				)
-				panic("error in getting players from source id")
-			}
-			teamPlayersId[teamId] = playerIds
+	// the event and everything saved for it are in one transaction, a failed match leaves nothing
+	// behind and its file is ingested again in the next run. teams and players are shared by the
+	// matches, they are saved before it so that the workers do not wait on each other's rows.
+	tx, err := service.DB.Begin(context.TODO())
+	if err != nil {
+		service.Logger.Info("error in starting transaction of match", zap.Int("file id", fileId), zap.Error(err))
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
+	}
+	defer func(tx pgx.Tx) {
+		_ = tx.Rollback(context.TODO())
+	}(tx)
+	failed := func(err error) ingestResult {
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusFailed, err: err}
+	}
+
+	eventId, err := saveEvent(eventData, tx)
+	if errors.Is(err, ErrEventExists) {
+		service.Logger.Info("skipping file as it is saved by another run", zap.String("filename", jsonFilePath))
+		return ingestResult{path: jsonFilePath, fileId: fileId, status: statusExists}
+	}
+	if err != nil {
+		service.Logger.Info(
+			"error in storing event",
+			zap.Int("file id", fileId),
+			zap.String("event", eventData.Name),
+			zap.Error(err))
+		return failed(err)
+	}
+	service.Logger.Info(
+		"completed saving event for match",
+		zap.String("event", eventData.Name),
+		zap.Int("file id", fileId),
+	)
+
+	err = saveMatchPlayers(eventId, teamPlayers, jsonData.Info.Registry, tx)
+	if err != nil {
+		service.Logger.Info(
+			"error in storing match players",
+			zap.Int("file id", fileId),
+			zap.Error(err))
+		return failed(err)
+	}
+
+	endResult := buildEndResult(eventId, jsonData, teamInfo, teamPlayers)
+	err = saveEndResult(endResult, tx)
+	if err != nil {
+		service.Logger.Info(
+			"error in storing end result",
+			zap.Int("file id", fileId),
+			zap.Error(err))
+		return failed(err)
+	}
+	// plan how to skip if already inserted
+	for inningsIndex, data := range jsonData.Innings {
+		teamId := teamInfo[data.Team]
+		inningsData := inningsSql{
+			Event:       eventId,
+			Ordinal:     inningsIndex + 1,
+			BattingTeam: teamId,
+			Declared:    data.Declared,
+			Forfeited:   data.Forfeited,
+			SuperOver:   data.SuperOver,
 		}
-		if breakParsing {
-			// skip the entire match
-			parsedMatchesBrokenCount += 1
-			continue
+		if data.Target != nil {
+			inningsData.TargetRuns = data.Target.Runs
+			inningsData.TargetOvers = data.Target.Overs
+			inningsData.TargetRevised = isTargetRevised(inningsIndex, jsonData)
 		}
-
-		filename := strings.Split(filepath.Base(jsonFilePath), ".json")[0]
-		basePath, _ := strconv.Atoi(filename)
-
-		tossAsString := fmt.Sprintf(
-			"%v won the toss and chose to %v", jsonData.Info.Toss["winner"], jsonData.Info.Toss["decision"])
-
-		eventData := eventSql{
-			FileId:        basePath,
-			MatchId:       jsonData.Info.MatchTypeNumber,
-			Name:          jsonData.Info.MatchEvent.Name,
-			Date:          jsonData.Info.Dates[0],
-			TeamA:         teamInfo[jsonData.Info.Teams[0]],
-			TeamB:         teamInfo[jsonData.Info.Teams[1]],
-			PlayingXiAIds: teamPlayersId[teamInfo[jsonData.Info.Teams[0]]],
-			PlayingXiBIds: teamPlayersId[teamInfo[jsonData.Info.Teams[1]]],
-			Venue:         jsonData.Info.Venue,
-			Toss:          tossAsString, // adding it as a string for now.
-			Overs:         jsonData.Info.Overs,
-			MatchType:     jsonData.Info.MatchType,
+		inningsId, err := saveInnings(inningsData, tx)
+		if err != nil {
+			service.Logger.Info(
+				"error in saving innings",
+				zap.Int("file id", fileId),
+				zap.Int("innings", inningsData.Ordinal),
+				zap.Error(err))
+			return failed(err)
 		}
-
-		eventId, err := saveEvent(eventData, service.DB)
+		err = savePowerplays(inningsId, data.Powerplays, tx)
 		if err != nil {
 			service.Logger.Info(
-				"error in storing event",
-				zap.Int("match id", jsonData.Info.MatchTypeNumber),
-				zap.String("event", eventData.Name),
+				"error in saving powerplays",
+				zap.Int("file id", fileId),
+				zap.Int("innings", inningsData.Ordinal),
 				zap.Error(err))
-			panic("error in storing event")
-			// cannot continue without event ID
+			return failed(err)
 		}
-		service.Logger.Info(
-			"completed saving event for match",
-			zap.String("event", eventData.Name),
-			zap.Int("match id", jsonData.Info.MatchTypeNumber),
-		)
 
-		teamPlayers := getPlayersBasedOnMatch(jsonData.Info.MatchTypeNumber, service)
-		// plan how to skip if already inserted
-		for _, data := range jsonData.Innings {
-			teamId := teamInfo[data.Team]
-			ballCount := 0
-			for _, overInfo := range data.Over {
-				overCount := overInfo.OverCount
-				for i, deliveryInfo := range overInfo.Deliveries {
-					var wicketId int
-					if deliveryInfo.Wicket != nil {
-						wicketData := wicketSql{
-							player: teamPlayers[deliveryInfo.Wicket[0].PlayerOut],
-							bowler: teamPlayers[deliveryInfo.Bowler],
-							kind:   deliveryInfo.Wicket[0].Kind,
-							event:  eventId,
-						}
-
-						wicketId, err = addWicket(wicketData, service)
-						if err != nil {
-							service.Logger.Info(
-								"error in saving wicket",
-								zap.Error(err),
-								zap.String("player out", deliveryInfo.Wicket[0].PlayerOut),
-								zap.String("bowler", deliveryInfo.Bowler),
-								zap.Any("other detail", teamPlayers),
-								zap.Int("match id", jsonData.Info.MatchTypeNumber),
-							)
-							panic("error in saving wickets")
-						}
+		ballCount := 0
+		for _, overInfo := range data.Over {
+			overCount := overInfo.OverCount
+			legalBalls := 0
+			for i, deliveryInfo := range overInfo.Deliveries {
+				ballCount += 1
+				// wides and no-balls are not counted in the over, they carry the number of the next legal ball
+				isLegal := deliveryInfo.Extras.Wides == 0 && deliveryInfo.Extras.NoBalls == 0
+				legalBall := legalBalls + 1
+				if isLegal {
+					legalBalls += 1
+				}
+
+				ballInfo := ballInfoSql{
+					Event:       eventId,
+					Innings:     inningsId,
+					Over:        overCount,
+					Ball:        i,
+					Delivery:    ballCount,
+					LegalBall:   legalBall,
+					IsLegal:     isLegal,
+					OverLabel:   fmt.Sprintf("%d.%d", overCount, legalBall),
+					BattingTeam: teamId,
+					Batsman:     teamPlayers[deliveryInfo.Batter],
+					Bowler:      teamPlayers[deliveryInfo.Bowler],
+					NonStriker:  teamPlayers[deliveryInfo.NonStriker],
+					StrikerRun:  deliveryInfo.Runs.Batter,
+					ExtraRun:    deliveryInfo.Runs.Extras,
+					Wides:       deliveryInfo.Extras.Wides,
+					NoBalls:     deliveryInfo.Extras.NoBalls,
+					Byes:        deliveryInfo.Extras.Byes,
+					LegByes:     deliveryInfo.Extras.LegByes,
+					Penalty:     deliveryInfo.Extras.Penalty,
+				}
+				ballId, err := saveBallInfo(ballInfo, tx)
+				if err != nil {
+					service.Logger.Info("error in saving ball", zap.Error(err))
+					return failed(err)
+				}
+
+				// a delivery can have more than one wicket, eg: run out and retired on the same ball
+				for _, wicket := range deliveryInfo.Wicket {
+					wicketData := wicketSql{
+						player:   teamPlayers[wicket.PlayerOut],
+						bowler:   teamPlayers[deliveryInfo.Bowler],
+						kind:     wicket.Kind,
+						event:    eventId,
+						ballInfo: ballId,
 					}
-					ballInfo := ballInfoSql{
-						Event:       eventId,
-						Over:        overCount,
-						Ball:        i,
-						BattingTeam: teamId,
-						Batsman:     teamPlayers[deliveryInfo.Batter],
-						Bowler:      teamPlayers[deliveryInfo.Bowler],
-						NonStriker:  teamPlayers[deliveryInfo.NonStriker],
-						StrikerRun:  deliveryInfo.Runs.Batter,
-						ExtraRun:    deliveryInfo.Runs.Extras,
-						Wickets:     wicketId,
+					for _, fielder := range wicket.Fielders {
+						wicketData.fielders = append(wicketData.fielders, fielderSql{
+							player:     teamPlayers[fielder.Name],
+							name:       fielder.Name,
+							substitute: fielder.Substitute,
+						})
 					}
-					ballCount += 1
-					err = saveBallInfo(ballInfo, service)
+
+					_, err = addWicket(wicketData, tx)
 					if err != nil {
-						service.Logger.Info("error in saving ball", zap.Error(err))
-						panic("error in saving ball")
+						service.Logger.Info(
+							"error in saving wicket",
+							zap.Error(err),
+							zap.String("player out", wicket.PlayerOut),
+							zap.String("bowler", deliveryInfo.Bowler),
+							zap.Any("other detail", teamPlayers),
+							zap.Int("file id", fileId),
+						)
+						return failed(err)
+					}
+				}
+				service.Logger.Info("saved ball info successfully")
+			}
+		}
+		service.Logger.Info(
+			"saved all the information of the match",
+			zap.Int("file id", fileId),
+			zap.Int("team id", teamId),
+			zap.Int("over count", len(data.Over)),
+			zap.Int("ballCount", ballCount),
+		)
+	}
+	if err = tx.Commit(context.TODO()); err != nil {
+		service.Logger.Info("error in committing match", zap.Int("file id", fileId), zap.Error(err))
+		return failed(err)
+	}
+	return ingestResult{path: jsonFilePath, fileId: fileId, status: statusIngested}
+}
+
+// seasonAsString season is a number (2023) in some files and a string (2022/23) in others
+func seasonAsString(season any) string {
+	switch value := season.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.Itoa(int(value))
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// resultCategory one of won, tie, draw or no result
+func resultCategory(outcome jsonparser.Outcome) string {
+	if outcome.Result != "" {
+		return outcome.Result
+	}
+	return "won"
+}
+
+// resultSummary human readable result, eg: India won by 7 wickets (D/L)
+func resultSummary(outcome jsonparser.Outcome) string {
+	var summary string
+	switch {
+	case outcome.Result == "tie":
+		summary = "Match tied"
+	case outcome.Result == "draw":
+		summary = "Match drawn"
+	case outcome.Result == "no result":
+		summary = "No result"
+	case outcome.By.Innings > 0:
+		summary = fmt.Sprintf("%s won by an innings and %d runs", outcome.Winner, outcome.By.Runs)
+	case outcome.By.Runs > 0:
+		summary = fmt.Sprintf("%s won by %d runs", outcome.Winner, outcome.By.Runs)
+	case outcome.By.Wickets > 0:
+		summary = fmt.Sprintf("%s won by %d wickets", outcome.Winner, outcome.By.Wickets)
+	default:
+		summary = fmt.Sprintf("%s won", outcome.Winner)
+	}
+
+	if outcome.Eliminator != "" {
+		summary += fmt.Sprintf(" (%s won the eliminator)", outcome.Eliminator)
+	}
+	if outcome.BowlOut != "" {
+		summary += fmt.Sprintf(" (%s won the bowl out)", outcome.BowlOut)
+	}
+	if outcome.Method != "" {
+		summary += fmt.Sprintf(" (%s)", outcome.Method)
+	}
+	return summary
+}
+
+// teamTotal runs and wickets of the team across its innings
+func teamTotal(team string, innings []jsonparser.Innings) (int, int) {
+	runs, wickets := 0, 0
+	for _, data := range innings {
+		if data.Team != team {
+			continue
+		}
+		for _, overInfo := range data.Over {
+			for _, deliveryInfo := range overInfo.Deliveries {
+				runs += deliveryInfo.Runs.Total
+				for _, wicket := range deliveryInfo.Wicket {
+					if wicket.Kind != "retired hurt" {
+						wickets += 1
 					}
-					service.Logger.Info("saved ball info successfully")
 				}
 			}
-			service.Logger.Info(
-				"saved all the information of the match",
-				zap.Int("match id", jsonData.Info.MatchTypeNumber),
-				zap.Int("team id", teamId),
-				zap.Int("over count", len(data.Over)),
-				zap.Int("ballCount", ballCount),
-			)
 		}
 	}
-	fmt.Println("skipped items", skippedItems, "parsedMatchesBrokenCount", parsedMatchesBrokenCount)
+	return runs, wickets
+}
+
+// isTargetRevised whether the target of the innings differs from the one set by the earlier innings,
+// eg: when it is revised by D/L. target is expected to be the runs of the opponent minus the runs of
+// the team in the earlier innings plus one, to be chased in the scheduled overs. super overs are
+// compared only with the earlier super overs.
+func isTargetRevised(inningsIndex int, jsonData baseStruct) bool {
+	current := jsonData.Innings[inningsIndex]
+	if current.Target == nil {
+		return false
+	}
+	if !current.SuperOver && jsonData.Info.Overs > 0 && current.Target.Overs != float64(jsonData.Info.Overs) {
+		return true
+	}
+
+	expectedTarget := 1
+	for _, data := range jsonData.Innings[:inningsIndex] {
+		if data.SuperOver != current.SuperOver {
+			continue
+		}
+		runs, _ := teamTotal(data.Team, []jsonparser.Innings{data})
+		if data.Team == current.Team {
+			expectedTarget -= runs
+		} else {
+			expectedTarget += runs
+		}
+	}
+	return current.Target.Runs != expectedTarget
+}
+
+// buildEndResult computes the result of the match from the outcome and innings of the match file
+func buildEndResult(
+	eventId int, jsonData baseStruct, teamInfo map[string]int, teamPlayers map[string]int,
+) endResultSql {
+	outcome := jsonData.Info.Outcome
+	endResult := endResultSql{
+		Event:        eventId,
+		Result:       resultCategory(outcome),
+		Summary:      resultSummary(outcome),
+		TeamWon:      teamInfo[outcome.Winner],
+		WinByRuns:    outcome.By.Runs,
+		WinByWickets: outcome.By.Wickets,
+		WinByInnings: outcome.By.Innings,
+		Method:       outcome.Method,
+		Eliminator:   teamInfo[outcome.Eliminator],
+		BowlOut:      teamInfo[outcome.BowlOut],
+	}
+	endResult.TeamAScore, endResult.TeamAWickets = teamTotal(jsonData.Info.Teams[0], jsonData.Innings)
+	endResult.TeamBScore, endResult.TeamBWickets = teamTotal(jsonData.Info.Teams[1], jsonData.Innings)
+	if len(jsonData.Info.PlayerOfMatch) > 0 {
+		// only the first player is stored when the award is shared
+		endResult.PlayerOfTheMatch = teamPlayers[jsonData.Info.PlayerOfMatch[0]]
+	}
+	return endResult
+}
+
+func saveEndResult(endResult endResultSql, tx pgx.Tx) error {
+	sqlQuery := `
+		INSERT INTO end_result (
+			event,
+			result,
+			summary,
+			team_won,
+			win_by_runs,
+			win_by_wickets,
+			win_by_innings,
+			method,
+			eliminator,
+			bowl_out,
+			team_a_score,
+			team_a_wickets,
+			team_b_score,
+			team_b_wickets,
+			player_of_the_match
+		)
+		VALUES (
+			@event,
+			@result,
+			@summary,
+			@team_won,
+			@win_by_runs,
+			@win_by_wickets,
+			@win_by_innings,
+			@method,
+			@eliminator,
+			@bowl_out,
+			@team_a_score,
+			@team_a_wickets,
+			@team_b_score,
+			@team_b_wickets,
+			@player_of_the_match
+		)
+		ON CONFLICT (event) DO NOTHING`
+
+	namedArgs := pgx.NamedArgs{
+		"event":          endResult.Event,
+		"result":         endResult.Result,
+		"summary":        endResult.Summary,
+		"win_by_runs":    endResult.WinByRuns,
+		"win_by_wickets": endResult.WinByWickets,
+		"win_by_innings": endResult.WinByInnings,
+		"method":         endResult.Method,
+		"team_a_score":   endResult.TeamAScore,
+		"team_a_wickets": endResult.TeamAWickets,
+		"team_b_score":   endResult.TeamBScore,
+		"team_b_wickets": endResult.TeamBWickets,
+	}
+	// team and player references are stored as null when not available
+	if endResult.TeamWon != 0 {
+		namedArgs["team_won"] = endResult.TeamWon
+	}
+	if endResult.Eliminator != 0 {
+		namedArgs["eliminator"] = endResult.Eliminator
+	}
+	if endResult.BowlOut != 0 {
+		namedArgs["bowl_out"] = endResult.BowlOut
+	}
+	if endResult.PlayerOfTheMatch != 0 {
+		namedArgs["player_of_the_match"] = endResult.PlayerOfTheMatch
+	}
+
+	_, err := tx.Exec(context.TODO(), sqlQuery, namedArgs)
+	return err
 }
 
-func isMatchDataExists(matchId int, service *app.App) bool {
-	sqlQuery := `SELECT EXISTS(SELECT 1 FROM event where match_id = $1)`
+func isMatchDataExists(fileId int, service *app.App) bool {
+	sqlQuery := `SELECT EXISTS(SELECT 1 FROM event where file_id = $1)`
 	var exists bool
-	err := service.DB.QueryRow(context.TODO(), sqlQuery, matchId).Scan(&exists)
+	err := service.DB.QueryRow(context.TODO(), sqlQuery, fileId).Scan(&exists)
 	if err != nil {
-		service.Logger.Info("error in checking match data exists", zap.Int("match id", matchId))
+		service.Logger.Info("error in checking match data exists", zap.Int("file id", fileId))
 		return false
 	}
 	return exists
 }
 
-func addWicket(wicketSqlData wicketSql, service *app.App) (int, error) {
-	sqlQuery := `INSERT INTO wicket (player, bowler, event, kind) VALUES (@player, @bowler, @event, @kind) RETURNING id`
+func addWicket(wicketSqlData wicketSql, tx pgx.Tx) (int, error) {
+	sqlQuery := `
+		INSERT INTO wicket (player, bowler, event, kind, ball_info)
+		VALUES (@player, @bowler, @event, @kind, @ball_info) RETURNING id`
 	namedArgs := pgx.NamedArgs{
-		"player": wicketSqlData.player,
-		"bowler": wicketSqlData.bowler,
-		"event":  wicketSqlData.event,
-		"kind":   wicketSqlData.kind,
+		"player":    wicketSqlData.player,
+		"bowler":    wicketSqlData.bowler,
+		"event":     wicketSqlData.event,
+		"kind":      wicketSqlData.kind,
+		"ball_info": wicketSqlData.ballInfo,
 	}
 
 	var id int
-	err := service.DB.QueryRow(context.Background(), sqlQuery, namedArgs).Scan(&id)
+	err := tx.QueryRow(context.Background(), sqlQuery, namedArgs).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	err = saveWicketFielders(id, wicketSqlData.fielders, tx)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
-func getPlayersBasedOnMatch(matchId int, service *app.App) map[string]int {
-	sqlQuery := `SELECT p.id, p.name FROM event AS e JOIN player as p ON p.id = ANY(e.playing_11_a_ids) or p.id = ANY(e.playing_11_b_ids) where match_id = (@match_id)`
-
-	namedArgs := pgx.NamedArgs{"match_id": matchId}
-	rows, err := service.DB.Query(context.Background(), sqlQuery, namedArgs)
-	if err != nil {
-		service.Logger.Info(
-			"error in fetching players based on match",
-			zap.Error(err),
-			zap.Int("match_id", matchId),
-		)
+func saveWicketFielders(wicketId int, fielders []fielderSql, tx pgx.Tx) error {
+	if len(fielders) == 0 {
 		return nil
 	}
-	players := make(map[string]int)
-	if rows != nil {
-		for rows.Next() {
-			var id int
-			var name string
-			err = rows.Scan(&id, &name)
-			if err != nil {
-				service.Logger.Info("players fetched, but error in looping rows")
-			}
-			players[name] = id
+	sqlQuery := `INSERT INTO wicket_fielder (wicket, player, name, substitute) VALUES (@wicket, @player, @name, @substitute)`
+
+	batch := pgx.Batch{}
+	for _, fielder := range fielders {
+		namedArgs := pgx.NamedArgs{"wicket": wicketId, "name": fielder.name, "substitute": fielder.substitute}
+		if fielder.player != 0 {
+			namedArgs["player"] = fielder.player
 		}
+		batch.Queue(sqlQuery, namedArgs)
 	}
-	defer rows.Close()
-	return players
+	return tx.SendBatch(context.Background(), &batch).Close()
 }
 
 func saveTeam(teamNames []string, dbInstance *pgxpool.Pool) (map[string]int, error) {
 	response := make(map[string]int)
 	for _, name := range teamNames {
-		sqlQuery := `INSERT INTO team (name) VALUES (@name) ON CONFLICT (name) DO NOTHING RETURNING (id)`
+		// updating on conflict returns the id of the existing team, even when another worker is saving the same team
+		sqlQuery := `INSERT INTO team (name) VALUES (@name) ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name RETURNING (id)`
 		namedArgs := pgx.NamedArgs{"name": name}
 		var id int
 		err := dbInstance.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
 		if err != nil {
-			sqlQuery = `SELECT id from team WHERE name = (@name)`
-			err = dbInstance.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
-			if err != nil {
-				return nil, err
-			}
+			return nil, err
 		}
 		response[name] = id
 	}
@@ -349,11 +797,16 @@ func saveTeam(teamNames []string, dbInstance *pgxpool.Pool) (map[string]int, err
 
 func savePlayersBulk(playersInfo map[string]string, teamId int, dbInstance *pgxpool.Pool) error {
 	// No need to return response, this is just storing all the players
-	sqlQuery := `INSERT INTO player (name, source_id, team_id) VALUES (@name, @source_id, @team_id) ON CONFLICT (source_id) DO NOTHING RETURNING (id)`
+	// source_id merged into another player lives only in player_alias, it should not create the player again
+	sqlQuery := `
+		INSERT INTO player (name, source_id, team_id)
+		SELECT @name, @source_id, @team_id
+		WHERE NOT EXISTS(SELECT 1 FROM player_alias WHERE source_id = @source_id)
+		ON CONFLICT (source_id) DO NOTHING RETURNING (id)`
 
 	batch := pgx.Batch{}
-	for sourceId, player := range playersInfo {
-		namedArgs := pgx.NamedArgs{"name": player, "source_id": sourceId, "team_id": teamId}
+	for _, sourceId := range sortedKeys(playersInfo) {
+		namedArgs := pgx.NamedArgs{"name": playersInfo[sourceId], "source_id": sourceId, "team_id": teamId}
 		batch.Queue(sqlQuery, namedArgs)
 	}
 
@@ -377,26 +830,7 @@ func savePlayersBulk(playersInfo map[string]string, teamId int, dbInstance *pgxp
 	return nil
 }
 
-func getPlayersBasedOnSourceId(sourceId []string, dbInstance *pgxpool.Pool) ([]int, error) {
-	sqlQuery := `SELECT id FROM player as p WHERE p.source_id = ANY($1)`
-	// pass the value directly in query when using $. use namedArgs only when using name in sqlQuery
-	rows, err := dbInstance.Query(context.TODO(), sqlQuery, sourceId)
-	defer rows.Close()
-
-	if err != nil {
-		return nil, err
-	}
-
-	var IDs []int
-	for rows.Next() {
-		var id int
-		_ = rows.Scan(&id)
-		IDs = append(IDs, id)
-	}
-	return IDs, nil
-}
-
-func saveEvent(event eventSql, dbInstance *pgxpool.Pool) (int, error) {
+func saveEvent(event eventSql, tx pgx.Tx) (int, error) {
 	sqlQuery := `
 		INSERT INTO event (
 			file_id,
@@ -410,7 +844,10 @@ func saveEvent(event eventSql, dbInstance *pgxpool.Pool) (int, error) {
 			venue,
 			toss,
 			overs,
-			match_type
+			match_type,
+			season,
+			gender,
+			team_type
 		)
 		VALUES (
 			@file_id,
@@ -424,17 +861,15 @@ func saveEvent(event eventSql, dbInstance *pgxpool.Pool) (int, error) {
 			@venue,
 			@toss,
 			@overs,
-			@match_type
+			@match_type,
+			@season,
+			@gender,
+			@team_type
 		)
-		ON CONFLICT (match_id) DO UPDATE SET name = @name RETURNING (id)`
-	/*
-		using DO UPDATE ON CONFLICT is not a correct approach, modify it later as per below link
-		https://dba.stackexchange.com/questions/129522/how-to-get-the-id-of-the-conflicting-row-in-upsert?newreg=73012b692b4f484d8406e4f67dd98ea6
-	*/
+		ON CONFLICT (file_id) DO NOTHING RETURNING (id)`
 
 	namedArgs := pgx.NamedArgs{
 		"file_id":          event.FileId,
-		"match_id":         event.MatchId,
 		"name":             event.Name,
 		"date":             event.Date,
 		"team_a":           event.TeamA,
@@ -445,64 +880,166 @@ func saveEvent(event eventSql, dbInstance *pgxpool.Pool) (int, error) {
 		"toss":             event.Toss,
 		"overs":            event.Overs,
 		"match_type":       event.MatchType,
+		"season":           event.Season,
+		"gender":           event.Gender,
+		"team_type":        event.TeamType,
+	}
+
+	// match_type_number is stored only for international matches
+	if event.MatchId != 0 {
+		namedArgs["match_id"] = event.MatchId
+	}
+
+	// no row is returned when another run saved the match after it was checked
+	var id int
+	err := tx.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, ErrEventExists
+	}
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func saveInnings(innings inningsSql, tx pgx.Tx) (int, error) {
+	sqlQuery := `
+		INSERT INTO innings (
+			event,
+			ordinal,
+			batting_team,
+			target_runs,
+			target_overs,
+			target_revised,
+			declared,
+			forfeited,
+			super_over
+		)
+		VALUES (
+			@event,
+			@ordinal,
+			@batting_team,
+			@target_runs,
+			@target_overs,
+			@target_revised,
+			@declared,
+			@forfeited,
+			@super_over
+		)
+		RETURNING id`
+
+	namedArgs := pgx.NamedArgs{
+		"event":          innings.Event,
+		"ordinal":        innings.Ordinal,
+		"batting_team":   innings.BattingTeam,
+		"declared":       innings.Declared,
+		"forfeited":      innings.Forfeited,
+		"super_over":     innings.SuperOver,
+		"target_revised": innings.TargetRevised,
+	}
+	if innings.TargetRuns != 0 {
+		namedArgs["target_runs"] = innings.TargetRuns
+		namedArgs["target_overs"] = innings.TargetOvers
 	}
 
 	var id int
-	err := dbInstance.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
+	err := tx.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
 	if err != nil {
 		return 0, err
 	}
 	return id, nil
 }
 
-func saveBallInfo(ballInfo ballInfoSql, service *app.App) error {
+func savePowerplays(inningsId int, powerplays []jsonparser.Powerplay, tx pgx.Tx) error {
+	if len(powerplays) == 0 {
+		return nil
+	}
+	sqlQuery := `INSERT INTO powerplay (innings, from_over, to_over, type) VALUES (@innings, @from_over, @to_over, @type)`
+
+	batch := pgx.Batch{}
+	for _, powerplay := range powerplays {
+		namedArgs := pgx.NamedArgs{
+			"innings":   inningsId,
+			"from_over": powerplay.From,
+			"to_over":   powerplay.To,
+			"type":      powerplay.Type,
+		}
+		batch.Queue(sqlQuery, namedArgs)
+	}
+	return tx.SendBatch(context.Background(), &batch).Close()
+}
+
+func saveBallInfo(ballInfo ballInfoSql, tx pgx.Tx) (int, error) {
 	sqlQuery := `
 		INSERT INTO ball_info (
 							event,
+							innings,
 							over,
 							ball,
+							delivery,
+							legal_ball,
+							is_legal,
+							over_label,
 							batting_team,
 							batsman,
 							bowler,
 							non_striker,
 							striker_run,
 							extra_run,
-							wicket
+							wides,
+							noballs,
+							byes,
+							legbyes,
+							penalty
 							)
 							VALUES (
 							@event,
+							@innings,
 							@over,
 							@ball,
+							@delivery,
+							@legal_ball,
+							@is_legal,
+							@over_label,
 							@batting_team,
 							@batsman,
 							@bowler,
 							@non_striker,
 							@striker_run,
 							@extra_run,
-							@wicket
-		        )`
+							@wides,
+							@noballs,
+							@byes,
+							@legbyes,
+							@penalty
+		        ) RETURNING id`
 
 	namedArgs := pgx.NamedArgs{
 		"event":        ballInfo.Event,
+		"innings":      ballInfo.Innings,
 		"over":         ballInfo.Over,
 		"ball":         ballInfo.Ball,
+		"delivery":     ballInfo.Delivery,
+		"legal_ball":   ballInfo.LegalBall,
+		"is_legal":     ballInfo.IsLegal,
+		"over_label":   ballInfo.OverLabel,
 		"batting_team": ballInfo.BattingTeam,
 		"batsman":      ballInfo.Batsman,
 		"bowler":       ballInfo.Bowler,
 		"non_striker":  ballInfo.NonStriker,
 		"striker_run":  ballInfo.StrikerRun,
 		"extra_run":    ballInfo.ExtraRun,
-		//"wicket":       nil,
-	}
-	if ballInfo.Wickets != 0 {
-		namedArgs["wicket"] = ballInfo.Wickets
+		"wides":        ballInfo.Wides,
+		"noballs":      ballInfo.NoBalls,
+		"byes":         ballInfo.Byes,
+		"legbyes":      ballInfo.LegByes,
+		"penalty":      ballInfo.Penalty,
 	}
 
-	rows, err := service.DB.Query(context.TODO(), sqlQuery, namedArgs)
-	defer rows.Close()
+	var id int
+	err := tx.QueryRow(context.TODO(), sqlQuery, namedArgs).Scan(&id)
 	if err != nil {
-		service.Logger.Info("error in saving ball info", zap.Error(err))
-		return err
+		return 0, err
 	}
-	return nil
+	return id, nil
 }
@@ -1,31 +1,184 @@
 package internal
 
-// currently not implemented. need a better planning. will do it later.
-func getNonUniqueNameInfo(sourceId string) string {
-	/*
-		json data is not accurate. some players names are differently mentioned in different files
-		where there are multiple players with same name. need to organise it with proper names and fetch using source_id.
-		currently not able to fetch players based on source_id because
-		json files contains names inside innings and not source_id.
-
-		eg : search as (2) (3) to get names with issues
-	*/
-	nonUniqueNames := make(map[string]string)
-	nonUniqueNames["d6dcb0c9"] = "Mohammad Shahzad Mohammadi"
-	nonUniqueNames["7b9b9aef"] = "Sher Muhammad Abdul Shakoor"
-	nonUniqueNames["e191bf68"] = "Ramiz Hasan Raja"
-
-	return nonUniqueNames[sourceId]
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	jsonparser "cricket/pkg/json_parser"
+)
+
+// duplicateNameSuffix cricsheet marks different players sharing a name within its data as
+// "Mohammad Shahzad (2)". the suffix is only for uniqueness of names inside the files.
+var duplicateNameSuffix = regexp.MustCompile(`\s*\(\d+\)$`)
+
+// displayName name of the player without the cricsheet duplicate marker
+func displayName(name string) string {
+	return duplicateNameSuffix.ReplaceAllString(name, "")
+}
+
+/*
+sortedKeys keys of the map in sorted order.
+
+statements of a batch run in a single transaction, rows shared between matches like players and aliases
+are saved in the same order by every worker, so that concurrent batches wait for each other instead of deadlocking.
+*/
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
-//func ReplaceNamesInPlayers() {
-//
-//}
-//
-//func ReplaceNamesInRegistry() {
-//
-//}
-//
-//func ReplaceNamesInInnings() {
-//
-//}
+/*
+resolveMatchPlayers maps the names used inside the match file to player ids.
+
+json data is not accurate across files, same player is spelled differently in different files
+and multiple players have the same name. names are unique within a match file and the registry
+of the match maps them to source_id, so players are always resolved by source_id through player_alias.
+names without a saved player (eg: umpires and match referees in the registry) are not part of the response.
+*/
+func resolveMatchPlayers(registry jsonparser.Registry, dbInstance *pgxpool.Pool) (map[string]int, error) {
+	sourceIds := make([]string, 0, len(registry.People))
+	for _, sourceId := range registry.People {
+		sourceIds = append(sourceIds, sourceId)
+	}
+
+	playerIds, err := playersBySourceId(sourceIds, dbInstance)
+	if err != nil {
+		return nil, err
+	}
+
+	players := make(map[string]int)
+	for name, sourceId := range registry.People {
+		if id, ok := playerIds[sourceId]; ok {
+			players[name] = id
+		}
+	}
+	return players, nil
+}
+
+var ErrUnresolvedPlayers = errors.New("players are not part of the registry or other matches")
+
+// registryFromAliases registry of older files without one, built by matching the names of the players with the
+// aliases saved from other matches and the cricsheet names.csv. a name of more than one source_id is not matched,
+// the registry is returned only when every player is matched.
+func registryFromAliases(players map[string][]string, dbInstance *pgxpool.Pool) (jsonparser.Registry, error) {
+	names := make([]string, 0)
+	for _, teamPlayers := range players {
+		names = append(names, teamPlayers...)
+	}
+	sqlQuery := `
+		SELECT name, MIN(source_id) FROM player_alias
+		WHERE name = ANY(@names)
+		GROUP BY name HAVING COUNT(DISTINCT source_id) = 1`
+	namedArgs := pgx.NamedArgs{"names": names}
+	rows, err := dbInstance.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return jsonparser.Registry{}, err
+	}
+	defer rows.Close()
+
+	registry := jsonparser.Registry{People: make(map[string]string)}
+	for rows.Next() {
+		var name, sourceId string
+		err = rows.Scan(&name, &sourceId)
+		if err != nil {
+			return jsonparser.Registry{}, err
+		}
+		registry.People[name] = sourceId
+	}
+	if err = rows.Err(); err != nil {
+		return jsonparser.Registry{}, err
+	}
+
+	missing := make([]string, 0)
+	for _, name := range names {
+		if _, ok := registry.People[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return jsonparser.Registry{}, fmt.Errorf("%w: %s", ErrUnresolvedPlayers, strings.Join(missing, ", "))
+	}
+	return registry, nil
+}
+
+// playersBySourceId player id of every source_id, all the aliases of a source_id belong to the same player
+func playersBySourceId(sourceIds []string, dbInstance *pgxpool.Pool) (map[string]int, error) {
+	sqlQuery := `SELECT DISTINCT player, source_id FROM player_alias WHERE source_id = ANY(@source_ids)`
+	namedArgs := pgx.NamedArgs{"source_ids": sourceIds}
+	rows, err := dbInstance.Query(context.TODO(), sqlQuery, namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	playerIds := make(map[string]int)
+	for rows.Next() {
+		var id int
+		var sourceId string
+		err = rows.Scan(&id, &sourceId)
+		if err != nil {
+			return nil, err
+		}
+		playerIds[sourceId] = id
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return playerIds, nil
+}
+
+// saveAliasesBulk stores the names of the match file against the player of their source_id.
+// aliases is name to source_id. player of a new source_id is the one saved with it in savePlayersBulk.
+func saveAliasesBulk(aliases map[string]string, dbInstance *pgxpool.Pool) error {
+	sqlQuery := `
+		INSERT INTO player_alias (player, source_id, name)
+		SELECT identity.player, @source_id, @name
+		FROM (
+		    SELECT pa.player FROM player_alias AS pa WHERE pa.source_id = @source_id
+		    UNION ALL
+		    SELECT p.id FROM player AS p WHERE p.source_id = @source_id
+		) AS identity
+		LIMIT 1
+		ON CONFLICT (source_id, name) DO NOTHING`
+
+	batch := pgx.Batch{}
+	for _, name := range sortedKeys(aliases) {
+		batch.Queue(sqlQuery, pgx.NamedArgs{"name": name, "source_id": aliases[name]})
+	}
+	return dbInstance.SendBatch(context.Background(), &batch).Close()
+}
+
+// saveMatchPlayers stores the resolved player of every name in the match, it is used to
+// attribute the match to the correct player when an identity is split later.
+func saveMatchPlayers(
+	eventId int, players map[string]int, registry jsonparser.Registry, tx pgx.Tx,
+) error {
+	sqlQuery := `
+		INSERT INTO match_player (event, player, source_id, name)
+		VALUES (@event, @player, @source_id, @name)
+		ON CONFLICT (event, name) DO UPDATE SET player = @player, source_id = @source_id`
+
+	batch := pgx.Batch{}
+	for name, playerId := range players {
+		namedArgs := pgx.NamedArgs{
+			"event":     eventId,
+			"player":    playerId,
+			"source_id": registry.People[name],
+			"name":      name,
+		}
+		batch.Queue(sqlQuery, namedArgs)
+	}
+	return tx.SendBatch(context.Background(), &batch).Close()
+}
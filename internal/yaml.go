@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// substituteSuffix old yaml files mark substitute fielders in the name, eg: "AB de Villiers (sub)"
+const substituteSuffix = " (sub)"
+
+func isYamlFile(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	return extension == ".yaml" || extension == ".yml"
+}
+
+/*
+decodeYamlMatch decodes a cricsheet yaml match file into the same structure as the json files.
+
+yaml files of recent data versions have the same layout as json. older files have the innings keyed by
+name and the deliveries keyed by over.ball, eg:
+
+	innings:
+	  - 1st innings:
+	      team: Kolkata Knight Riders
+	      deliveries:
+	        - 0.1:
+	            batsman: SC Ganguly
+	            wicket: {kind: caught, player_out: SC Ganguly, fielders: [JH Kallis]}
+
+these are converted to the json layout before decoding. older files do not have the registry and the players,
+players are taken from the deliveries and the registry is left empty, it is resolved while saving the match.
+*/
+func decodeYamlMatch(content []byte) (baseStruct, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return baseStruct{}, err
+	}
+	if len(document.Content) == 0 {
+		return baseStruct{}, errors.New("empty yaml document")
+	}
+
+	match, ok := yamlValue(document.Content[0]).(map[string]any)
+	if !ok {
+		return baseStruct{}, errors.New("yaml document is not a match")
+	}
+	if innings, ok := match["innings"].([]any); ok {
+		for index, item := range innings {
+			innings[index] = convertYamlInnings(item)
+		}
+	}
+
+	content, err = json.Marshal(match)
+	if err != nil {
+		return baseStruct{}, err
+	}
+	var data baseStruct
+	err = json.Unmarshal(content, &data)
+	if err != nil {
+		return baseStruct{}, err
+	}
+	if len(data.Info.Players) == 0 {
+		data.Info.Players = playersFromDeliveries(data)
+	}
+	return data, nil
+}
+
+// yamlValue converts the node into values which can be encoded as json. keys of mappings
+// are kept as written in the file, so that over.ball keys like 0.10 are not read as numbers.
+func yamlValue(node *yaml.Node) any {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return yamlValue(node.Content[0])
+	case yaml.AliasNode:
+		return yamlValue(node.Alias)
+	case yaml.MappingNode:
+		value := make(map[string]any, len(node.Content)/2)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value[node.Content[i].Value] = yamlValue(node.Content[i+1])
+		}
+		return value
+	case yaml.SequenceNode:
+		value := make([]any, 0, len(node.Content))
+		for _, item := range node.Content {
+			value = append(value, yamlValue(item))
+		}
+		return value
+	}
+
+	switch node.Tag {
+	case "!!null":
+		return nil
+	case "!!bool":
+		value, err := strconv.ParseBool(node.Value)
+		if err == nil {
+			return value
+		}
+	case "!!int":
+		value, err := strconv.ParseInt(node.Value, 0, 64)
+		if err == nil {
+			return value
+		}
+	case "!!float":
+		value, err := strconv.ParseFloat(node.Value, 64)
+		if err == nil {
+			return value
+		}
+	}
+	// strings and timestamps like dates of the match are kept as text
+	return node.Value
+}
+
+// convertYamlInnings converts an innings of the older layout, keyed by the innings name, into the json layout
+func convertYamlInnings(item any) any {
+	keyed, ok := item.(map[string]any)
+	if !ok || len(keyed) != 1 {
+		return item
+	}
+	var name string
+	var innings map[string]any
+	for key, value := range keyed {
+		name = key
+		innings, ok = value.(map[string]any)
+	}
+	if !ok {
+		return item
+	}
+	deliveries, ok := innings["deliveries"].([]any)
+	if !ok {
+		return item
+	}
+
+	converted := map[string]any{
+		"team":       innings["team"],
+		"overs":      convertYamlDeliveries(deliveries),
+		"declared":   yamlFlag(innings["declared"]),
+		"forfeited":  yamlFlag(innings["forfeited"]),
+		"super_over": yamlFlag(innings["super_over"]) || strings.Contains(strings.ToLower(name), "super over"),
+	}
+	for _, key := range []string{"target", "powerplays"} {
+		if value, ok := innings[key]; ok {
+			converted[key] = value
+		}
+	}
+	return converted
+}
+
+// convertYamlDeliveries groups the over.ball keyed deliveries into overs
+func convertYamlDeliveries(deliveries []any) []any {
+	overs := make([]any, 0)
+	var current map[string]any
+	for _, item := range deliveries {
+		keyed, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, value := range keyed {
+			delivery, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			over, err := strconv.Atoi(strings.Split(key, ".")[0])
+			if err != nil {
+				continue
+			}
+			if current == nil || current["over"] != over {
+				current = map[string]any{"over": over, "deliveries": make([]any, 0)}
+				overs = append(overs, current)
+			}
+			current["deliveries"] = append(current["deliveries"].([]any), convertYamlDelivery(delivery))
+		}
+	}
+	return overs
+}
+
+// convertYamlDelivery renames the keys of older files to the json keys, eg: batsman to batter
+func convertYamlDelivery(delivery map[string]any) map[string]any {
+	if batsman, ok := delivery["batsman"]; ok {
+		delivery["batter"] = batsman
+	}
+	if runs, ok := delivery["runs"].(map[string]any); ok {
+		if batsman, ok := runs["batsman"]; ok {
+			runs["batter"] = batsman
+		}
+	}
+
+	wickets := make([]any, 0)
+	switch wicket := delivery["wicket"].(type) {
+	case map[string]any:
+		wickets = append(wickets, wicket)
+	case []any:
+		wickets = append(wickets, wicket...)
+	}
+	if existing, ok := delivery["wickets"].([]any); ok {
+		wickets = append(wickets, existing...)
+	}
+	for _, item := range wickets {
+		wicket, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		if fielders, ok := wicket["fielders"].([]any); ok {
+			for index, fielder := range fielders {
+				fielders[index] = convertYamlFielder(fielder)
+			}
+		}
+	}
+	delivery["wickets"] = wickets
+	return delivery
+}
+
+// convertYamlFielder fielders are names in older files and objects with name and substitute in newer files
+func convertYamlFielder(fielder any) any {
+	name, ok := fielder.(string)
+	if !ok {
+		return fielder
+	}
+	if strings.HasSuffix(name, substituteSuffix) {
+		return map[string]any{"name": strings.TrimSuffix(name, substituteSuffix), "substitute": true}
+	}
+	return map[string]any{"name": name}
+}
+
+// yamlFlag flags are booleans in newer files and yes or no in older files
+func yamlFlag(value any) bool {
+	switch flag := value.(type) {
+	case bool:
+		return flag
+	case string:
+		return strings.EqualFold(flag, "yes") || strings.EqualFold(flag, "true")
+	}
+	return false
+}
+
+// playersFromDeliveries playing 11 of the teams from the deliveries, for older files without the players.
+// players who did not bat or bowl are not known in this case.
+func playersFromDeliveries(data baseStruct) map[string][]string {
+	players := make(map[string][]string)
+	seen := make(map[string]bool)
+	addPlayer := func(team string, name string) {
+		key := fmt.Sprintf("%s/%s", team, name)
+		if name == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+		players[team] = append(players[team], name)
+	}
+
+	for _, innings := range data.Innings {
+		bowlingTeam := ""
+		for _, team := range data.Info.Teams {
+			if team != innings.Team {
+				bowlingTeam = team
+			}
+		}
+		for _, over := range innings.Over {
+			for _, delivery := range over.Deliveries {
+				addPlayer(innings.Team, delivery.Batter)
+				addPlayer(innings.Team, delivery.NonStriker)
+				addPlayer(bowlingTeam, delivery.Bowler)
+			}
+		}
+	}
+	return players
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// testdata/211028.yaml is a cricsheet file of the older layout, without the registry and the players,
+// trimmed to a few deliveries of each innings
+func TestDecodeYamlMatchOldLayout(t *testing.T) {
+	content, err := os.ReadFile("testdata/211028.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := decodeYamlMatch(content)
+	if err != nil {
+		t.Fatalf("decodeYamlMatch error = %v", err)
+	}
+
+	info := data.Info
+	if info.MatchType != "T20" || info.Gender != "male" || info.Overs != 20 {
+		t.Errorf("info = %s %s %d overs, want T20 male 20 overs", info.MatchType, info.Gender, info.Overs)
+	}
+	if !slices.Equal(info.Dates, []string{"2005-02-17"}) {
+		t.Errorf("dates = %v, want [2005-02-17]", info.Dates)
+	}
+	if info.Outcome.Winner != "Australia" || info.Outcome.By.Runs != 44 {
+		t.Errorf("outcome = %+v, want Australia by 44 runs", info.Outcome)
+	}
+	if len(info.Registry.People) != 0 {
+		t.Errorf("registry = %v, want empty registry", info.Registry.People)
+	}
+
+	players := map[string][]string{
+		"Australia":   {"AC Gilchrist", "MJ Clarke", "B Lee"},
+		"New Zealand": {"KD Mills", "DR Tuffey", "SP Fleming", "NJ Astle"},
+	}
+	for team, want := range players {
+		if got := info.Players[team]; !slices.Equal(got, want) {
+			t.Errorf("players of %s = %v, want %v", team, got, want)
+		}
+	}
+
+	if len(data.Innings) != 2 {
+		t.Fatalf("innings = %d, want 2", len(data.Innings))
+	}
+	first := data.Innings[0]
+	if first.Team != "Australia" || first.SuperOver {
+		t.Errorf("first innings team = %s super over = %v, want Australia without super over", first.Team, first.SuperOver)
+	}
+	if len(first.Over) != 2 || first.Over[0].OverCount != 0 || first.Over[1].OverCount != 1 {
+		t.Fatalf("overs of first innings = %+v, want overs 0 and 1", first.Over)
+	}
+	if len(first.Over[0].Deliveries) != 3 {
+		t.Fatalf("deliveries of first over = %d, want 3", len(first.Over[0].Deliveries))
+	}
+	wide := first.Over[0].Deliveries[1]
+	if wide.Batter != "AC Gilchrist" || wide.Extras.Wides != 1 || wide.Runs.Total != 1 {
+		t.Errorf("second delivery = %+v, want a wide to AC Gilchrist", wide)
+	}
+	if runs := first.Over[0].Deliveries[2].Runs.Batter; runs != 4 {
+		t.Errorf("runs of batter on third delivery = %d, want 4", runs)
+	}
+
+	wickets := first.Over[1].Deliveries[0].Wicket
+	if len(wickets) != 1 || wickets[0].Kind != "caught" || wickets[0].PlayerOut != "MJ Clarke" {
+		t.Fatalf("wickets = %+v, want MJ Clarke caught", wickets)
+	}
+	fielders := wickets[0].Fielders
+	if len(fielders) != 1 || fielders[0].Name != "JDP Oram" || !fielders[0].Substitute {
+		t.Errorf("fielders = %+v, want substitute JDP Oram", fielders)
+	}
+
+	bowled := data.Innings[1].Over[0].Deliveries[1].Wicket
+	if len(bowled) != 1 || bowled[0].Kind != "bowled" || len(bowled[0].Fielders) != 0 {
+		t.Errorf("wickets of second innings = %+v, want NJ Astle bowled", bowled)
+	}
+}
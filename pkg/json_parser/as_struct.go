@@ -9,6 +9,22 @@ type MatchEvent struct {
 	Name        string `json:"name"`
 }
 
+type OutcomeBy struct {
+	Runs    int `json:"runs"`
+	Wickets int `json:"wickets"`
+	Innings int `json:"innings"`
+}
+
+// Outcome winner is empty when result is tie, draw or no result
+type Outcome struct {
+	Winner     string    `json:"winner"`
+	By         OutcomeBy `json:"by"`
+	Result     string    `json:"result"`
+	Method     string    `json:"method"`
+	Eliminator string    `json:"eliminator"`
+	BowlOut    string    `json:"bowl_out"`
+}
+
 type Info struct {
 	BallsPerOver    int                    `json:"balls_per_over"`
 	City            string                 `json:"city"`
@@ -18,7 +34,7 @@ type Info struct {
 	MatchType       string                 `json:"match_type"`
 	MatchTypeNumber int                    `json:"match_type_number"`
 	Officials       map[string]interface{} `json:"officials"`
-	Outcome         map[string]interface{} `json:"outcome"`
+	Outcome         Outcome                `json:"outcome"`
 	Overs           int                    `json:"overs"`
 	PlayerOfMatch   []string               `json:"player_of_match"`
 	Players         map[string][]string    `json:"players"`
@@ -37,10 +53,24 @@ type Run struct {
 	Total  int `json:"total"`
 }
 
+type Extras struct {
+	Wides   int `json:"wides"`
+	NoBalls int `json:"noballs"`
+	Byes    int `json:"byes"`
+	LegByes int `json:"legbyes"`
+	Penalty int `json:"penalty"`
+}
+
+// Fielder name is empty for some of the old files where fielder is not known
+type Fielder struct {
+	Name       string `json:"name"`
+	Substitute bool   `json:"substitute"`
+}
+
 type Wicket struct {
-	Kind      string `json:"kind"`
-	PlayerOut string `json:"player_out"`
-	//Fielder   []map[string]string `json:"fielders"`  need to work on it later.:TODO: V2
+	Kind      string    `json:"kind"`
+	PlayerOut string    `json:"player_out"`
+	Fielders  []Fielder `json:"fielders"`
 }
 
 type Delivery struct {
@@ -48,6 +78,7 @@ type Delivery struct {
 	Bowler     string   `json:"bowler"`
 	NonStriker string   `json:"non_striker"`
 	Runs       Run      `json:"runs"`
+	Extras     Extras   `json:"extras"`
 	Wicket     []Wicket `json:"wickets"`
 }
 
@@ -56,13 +87,26 @@ type MatchOver struct {
 	Deliveries []Delivery `json:"deliveries"`
 }
 
+// Target overs can be a partial over when the innings is reduced, eg: 17.3
 type Target struct {
-	Over int `json:"overs"`
-	Runs int `json:"runs"`
+	Overs float64 `json:"overs"`
+	Runs  int     `json:"runs"`
+}
+
+// Powerplay from and to are in over.ball notation, eg: 0.1 to 5.6. type is mandatory, batting or fielding
+type Powerplay struct {
+	From float64 `json:"from"`
+	To   float64 `json:"to"`
+	Type string  `json:"type"`
 }
 
+// Innings target is set only for the innings chasing a target
 type Innings struct {
-	Team string      `json:"team"`
-	Over []MatchOver `json:"overs"`
-	//Target Target      `json:"target"`
+	Team       string      `json:"team"`
+	Over       []MatchOver `json:"overs"`
+	Target     *Target     `json:"target"`
+	Powerplays []Powerplay `json:"powerplays"`
+	Declared   bool        `json:"declared"`
+	Forfeited  bool        `json:"forfeited"`
+	SuperOver  bool        `json:"super_over"`
 }
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cricket/internal"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type mergePlayerRequest struct {
+	DuplicateId int `json:"duplicate_id"`
+}
+
+type splitPlayerRequest struct {
+	SourceId string `json:"source_id"`
+}
+
+type renamePlayerRequest struct {
+	Name string `json:"name"`
+}
+
+// identityResponse common response of the player identity handlers
+func (service AppInstance) identityResponse(
+	c echo.Context, identity internal.PlayerIdentity, err error, action string, playerId int,
+) error {
+	if errors.Is(err, internal.ErrPlayerNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if errors.Is(err, internal.ErrSamePlayer) || errors.Is(err, internal.ErrAliasNotFound) ||
+		errors.Is(err, internal.ErrPrimarySourceId) || errors.Is(err, internal.ErrEmptyName) {
+		errorResponse := map[string]string{"error": err.Error()}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in " + action + " player!! Contact Admin"}
+		service.App.Logger.Info("error in "+action+" player", zap.Int("player_id", playerId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, identity)
+}
+
+func (service AppInstance) PlayerAliases(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	identity, err := internal.QueryPlayerIdentity(playerId, service.App)
+	return service.identityResponse(c, identity, err, "fetching", playerId)
+}
+
+func (service AppInstance) MergePlayer(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	var request mergePlayerRequest
+	if err = c.Bind(&request); err != nil || request.DuplicateId == 0 {
+		errorResponse := map[string]string{"error": "Invalid request!! duplicate_id is required"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	identity, err := internal.MergePlayers(playerId, request.DuplicateId, service.App)
+	return service.identityResponse(c, identity, err, "merging", playerId)
+}
+
+func (service AppInstance) SplitPlayer(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	var request splitPlayerRequest
+	if err = c.Bind(&request); err != nil || request.SourceId == "" {
+		errorResponse := map[string]string{"error": "Invalid request!! source_id is required"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	identity, err := internal.SplitPlayer(playerId, request.SourceId, service.App)
+	return service.identityResponse(c, identity, err, "splitting", playerId)
+}
+
+func (service AppInstance) RenamePlayer(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	var request renamePlayerRequest
+	if err = c.Bind(&request); err != nil {
+		errorResponse := map[string]string{"error": "Invalid request!! name is required"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	identity, err := internal.RenamePlayer(playerId, request.Name, service.App)
+	return service.identityResponse(c, identity, err, "renaming", playerId)
+}
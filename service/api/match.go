@@ -0,0 +1,56 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cricket/internal"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func (service AppInstance) MatchScorecard(c echo.Context) error {
+	matchId, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid match id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	scorecard, err := internal.QueryScorecard(matchId, service.App)
+	if errors.Is(err, internal.ErrMatchNotFound) {
+		errorResponse := map[string]string{"error": "Match not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching scorecard!! Contact Admin"}
+		service.App.Logger.Info("error in fetching scorecard", zap.Int("match_id", matchId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, scorecard)
+}
+
+func (service AppInstance) MatchChase(c echo.Context) error {
+	matchId, err := strconv.Atoi(c.Param("match_id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid match id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	chase, err := internal.QueryChase(matchId, service.App)
+	if errors.Is(err, internal.ErrMatchNotFound) {
+		errorResponse := map[string]string{"error": "Match not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if errors.Is(err, internal.ErrChaseNotFound) {
+		errorResponse := map[string]string{"error": "Match does not have a chase"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching chase!! Contact Admin"}
+		service.App.Logger.Info("error in fetching chase", zap.Int("match_id", matchId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, chase)
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cricket/internal"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func (service AppInstance) PlayerBatting(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	stats, err := internal.QueryPlayerBatting(playerId, filter, service.App)
+	if errors.Is(err, internal.ErrPlayerNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching batting stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching batting stats", zap.Int("player_id", playerId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (service AppInstance) PlayerBowling(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	stats, err := internal.QueryPlayerBowling(playerId, filter, service.App)
+	if errors.Is(err, internal.ErrPlayerNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching bowling stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching bowling stats", zap.Int("player_id", playerId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (service AppInstance) PlayerFielding(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	stats, err := internal.QueryPlayerFielding(playerId, filter, service.App)
+	if errors.Is(err, internal.ErrPlayerNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching fielding stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching fielding stats", zap.Int("player_id", playerId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+func (service AppInstance) PlayerPhases(c echo.Context) error {
+	playerId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid player id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	phases, err := internal.QueryPlayerPhases(playerId, filter, service.App)
+	if errors.Is(err, internal.ErrPlayerNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching phase stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching player phase stats", zap.Int("player_id", playerId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, phases)
+}
+
+// PlayerByIdentifier player with the identifier of another dataset, eg: /identifier/cricinfo/253802
+func (service AppInstance) PlayerByIdentifier(c echo.Context) error {
+	provider := c.Param("provider")
+	identifier := c.Param("identifier")
+
+	identity, err := internal.QueryPlayerByIdentifier(provider, identifier, service.App)
+	if errors.Is(err, internal.ErrIdentifierNotFound) {
+		errorResponse := map[string]string{"error": "Player not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching player!! Contact Admin"}
+		service.App.Logger.Info(
+			"error in fetching player by identifier",
+			zap.String("provider", provider), zap.String("identifier", identifier), zap.Error(err),
+		)
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, identity)
+}
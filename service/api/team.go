@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cricket/internal"
+
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+func (service AppInstance) TeamRecord(c echo.Context) error {
+	teamId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid team id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	record, err := internal.QueryTeamRecord(teamId, filter, service.App)
+	if errors.Is(err, internal.ErrTeamNotFound) {
+		errorResponse := map[string]string{"error": "Team not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching team record!! Contact Admin"}
+		service.App.Logger.Info("error in fetching team record", zap.Int("team_id", teamId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, record)
+}
+
+func (service AppInstance) TeamPhases(c echo.Context) error {
+	teamId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid team id"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	phases, err := internal.QueryTeamPhases(teamId, filter, service.App)
+	if errors.Is(err, internal.ErrTeamNotFound) {
+		errorResponse := map[string]string{"error": "Team not found"}
+		return c.JSON(http.StatusNotFound, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching phase stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching team phase stats", zap.Int("team_id", teamId), zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, phases)
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"time"
 
 	"cricket/cmd/app"
 	"cricket/internal"
@@ -14,9 +17,168 @@ type AppInstance struct {
 	App *app.App
 }
 
+const invalidFiltersError = "Invalid filters!! Dates should be in YYYY-MM-DD format and include_super_overs should be true or false"
+
+// statsFilterFromRequest reads the stats filters from query params.
+// dates are expected in YYYY-MM-DD format and include_super_overs as a boolean
+func statsFilterFromRequest(c echo.Context) (internal.StatsFilter, error) {
+	filter := internal.StatsFilter{
+		MatchType: c.QueryParam("match_type"),
+		Season:    c.QueryParam("season"),
+		Team:      c.QueryParam("team"),
+		Venue:     c.QueryParam("venue"),
+		Gender:    c.QueryParam("gender"),
+		TeamType:  c.QueryParam("team_type"),
+		EventName: c.QueryParam("event_name"),
+	}
+
+	var err error
+	if includeSuperOvers := c.QueryParam("include_super_overs"); includeSuperOvers != "" {
+		filter.IncludeSuperOvers, err = strconv.ParseBool(includeSuperOvers)
+		if err != nil {
+			return internal.StatsFilter{}, err
+		}
+	}
+	if fromDate := c.QueryParam("from_date"); fromDate != "" {
+		filter.FromDate, err = time.Parse(time.DateOnly, fromDate)
+		if err != nil {
+			return internal.StatsFilter{}, err
+		}
+	}
+	if toDate := c.QueryParam("to_date"); toDate != "" {
+		filter.ToDate, err = time.Parse(time.DateOnly, toDate)
+		if err != nil {
+			return internal.StatsFilter{}, err
+		}
+	}
+	return filter, nil
+}
+
+// paginationFromRequest reads page and page_size query params. missing values are left as zero
+// and defaults are applied while querying.
+func paginationFromRequest(c echo.Context) (internal.Pagination, error) {
+	pagination := internal.Pagination{}
+	var err error
+	if page := c.QueryParam("page"); page != "" {
+		pagination.Page, err = strconv.Atoi(page)
+		if err != nil {
+			return internal.Pagination{}, err
+		}
+	}
+	if pageSize := c.QueryParam("page_size"); pageSize != "" {
+		pagination.PageSize, err = strconv.Atoi(pageSize)
+		if err != nil {
+			return internal.Pagination{}, err
+		}
+	}
+	return pagination, nil
+}
+
+func (service AppInstance) ListTournaments(c echo.Context) error {
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	pagination, err := paginationFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid pagination!! page and page_size should be numbers"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	sortBy := c.QueryParam("sort_by")
+	if sortBy == "" {
+		sortBy = "start_date"
+	}
+	order := c.QueryParam("order")
+	if order == "" {
+		order = "desc"
+	}
+
+	tournaments, err := internal.QueryTournaments(filter, pagination, sortBy, order, service.App)
+	if errors.Is(err, internal.ErrInvalidSort) {
+		errorResponse := map[string]string{"error": "Invalid sort!! " + err.Error()}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching tournaments!! Contact Admin"}
+		service.App.Logger.Info("error in fetching tournaments", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, tournaments)
+}
+
+func (service AppInstance) FieldingLeaderboard(c echo.Context) error {
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	pagination, err := paginationFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": "Invalid pagination!! page and page_size should be numbers"}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	leaderboard, err := internal.QueryFieldingLeaderboard(filter, pagination, service.App)
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching fielding leaderboard!! Contact Admin"}
+		service.App.Logger.Info("error in fetching fielding leaderboard", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, leaderboard)
+}
+
+func (service AppInstance) TournamentPhases(c echo.Context) error {
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+
+	phases, err := internal.QueryTournamentPhases(filter, service.App)
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching phase stats!! Contact Admin"}
+		service.App.Logger.Info("error in fetching tournament phase stats", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, phases)
+}
+
+// defaultChaseBandSize targets are grouped in bands of these many runs when band_size is not given
+const defaultChaseBandSize = 20
+
+func (service AppInstance) TournamentChases(c echo.Context) error {
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	bandSize := defaultChaseBandSize
+	if band := c.QueryParam("band_size"); band != "" {
+		bandSize, err = strconv.Atoi(band)
+		if err != nil || bandSize < 1 {
+			errorResponse := map[string]string{"error": "Invalid band_size!! It should be a positive number"}
+			return c.JSON(http.StatusBadRequest, errorResponse)
+		}
+	}
+
+	chases, err := internal.QueryChaseAnalysis(filter, bandSize, service.App)
+	if err != nil {
+		errorResponse := map[string]string{"error": "Error in fetching chases!! Contact Admin"}
+		service.App.Logger.Info("error in fetching chase analysis", zap.Error(err))
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
+	return c.JSON(http.StatusOK, chases)
+}
+
 func (service AppInstance) TournamentStats(c echo.Context) error {
+	filter, err := statsFilterFromRequest(c)
+	if err != nil {
+		errorResponse := map[string]string{"error": invalidFiltersError}
+		return c.JSON(http.StatusBadRequest, errorResponse)
+	}
 
-	statsResponse, err := internal.QueryTournamentStats(service.App)
+	statsResponse, err := internal.QueryTournamentStats(filter, service.App)
 	if err != nil {
 		errorResponse := map[string]string{"error": "Error in fetching stats!! Contact Admin"}
 		service.App.Logger.Info("error in fetching stats", zap.Error(err))
@@ -9,5 +9,38 @@ import (
 
 func AddTournamentRouters(e *echo.Group, service *app.App) {
 	tournament := api.AppInstance{App: service}
+	e.GET("", tournament.ListTournaments)
 	e.GET("/stats", tournament.TournamentStats)
+	e.GET("/fielding", tournament.FieldingLeaderboard)
+	e.GET("/phases", tournament.TournamentPhases)
+	e.GET("/chases", tournament.TournamentChases)
+}
+
+func AddMatchRouters(e *echo.Group, service *app.App) {
+	match := api.AppInstance{App: service}
+	e.GET("/:match_id/scorecard", match.MatchScorecard)
+	e.GET("/:match_id/chase", match.MatchChase)
+}
+
+func AddPlayerRouters(e *echo.Group, service *app.App) {
+	player := api.AppInstance{App: service}
+	e.GET("/:id/batting", player.PlayerBatting)
+	e.GET("/:id/bowling", player.PlayerBowling)
+	e.GET("/:id/fielding", player.PlayerFielding)
+	e.GET("/:id/phases", player.PlayerPhases)
+	e.GET("/identifier/:provider/:identifier", player.PlayerByIdentifier)
+}
+
+func AddTeamRouters(e *echo.Group, service *app.App) {
+	team := api.AppInstance{App: service}
+	e.GET("/:id", team.TeamRecord)
+	e.GET("/:id/phases", team.TeamPhases)
+}
+
+func AddAdminRouters(e *echo.Group, service *app.App) {
+	admin := api.AppInstance{App: service}
+	e.GET("/player/:id/aliases", admin.PlayerAliases)
+	e.POST("/player/:id/merge", admin.MergePlayer)
+	e.POST("/player/:id/split", admin.SplitPlayer)
+	e.PUT("/player/:id/name", admin.RenamePlayer)
 }